@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+
+	"github.com/demolaemrick/social/internal/mailer"
+	"github.com/demolaemrick/social/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type UpdateProfilePayload struct {
+	DisplayName *string `json:"display_name" validate:"omitempty,max=100"`
+	Bio         *string `json:"bio" validate:"omitempty,max=500"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitempty,http_url,max=2048"`
+	Website     *string `json:"website" validate:"omitempty,http_url,max=2048"`
+}
+
+// UpdateProfile godoc
+//
+//	@Summary		Updates the authenticated user's profile
+//	@Description	Updates the display name, bio, avatar URL and website. Omitted fields are left unchanged; empty strings clear them.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		UpdateProfilePayload	true	"Profile fields"
+//	@Success		200		{object}	store.User
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me [patch]
+func (app *application) updateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUserFromCtx(r)
+
+	var payload UpdateProfilePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if payload.DisplayName != nil {
+		user.DisplayName = *payload.DisplayName
+	}
+	if payload.Bio != nil {
+		user.Bio = *payload.Bio
+	}
+	if payload.AvatarURL != nil {
+		user.AvatarURL = *payload.AvatarURL
+	}
+	if payload.Website != nil {
+		user.Website = *payload.Website
+	}
+
+	ctx := r.Context()
+
+	if err := app.store.Users.UpdateProfile(ctx, user); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	app.invalidateUser(ctx, user.ID)
+
+	if err := app.jsonResponse(w, http.StatusOK, user); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+type ChangePasswordPayload struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=72"`
+	NewPassword     string `json:"new_password" validate:"required,min=3,max=72"`
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Changes the authenticated user's password
+//	@Description	Changes the password after checking the current one
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ChangePasswordPayload	true	"Current and new password"
+//	@Success		204		{string}	string					"Password changed"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		403		{object}	error	"Current password is incorrect"
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/password [put]
+func (app *application) changePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload ChangePasswordPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	// The authenticated user may come from the cache, which doesn't keep the
+	// password hash.
+	user, err := app.store.Users.GetByID(ctx, getAuthUserFromCtx(r).ID)
+	if err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	if err := user.Password.Compare(payload.CurrentPassword); err != nil {
+		app.forbiddenError(w, r, "current password is incorrect")
+		return
+	}
+
+	if err := user.Password.Set(payload.NewPassword); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Users.UpdatePassword(ctx, user); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type ChangeEmailPayload struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// ChangeEmail godoc
+//
+//	@Summary		Requests an email change
+//	@Description	Starts changing the authenticated user's email. A confirmation link is sent to the new address, which takes effect once confirmed.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ChangeEmailPayload	true	"New email"
+//	@Success		202		{string}	string				"Email change pending confirmation"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/email [put]
+func (app *application) changeEmailHandler(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUserFromCtx(r)
+
+	var payload ChangeEmailPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	// The address is checked again when the change is confirmed; this only
+	// saves the user a pointless round trip.
+	_, err := app.store.Users.GetByEmail(ctx, payload.Email)
+	switch err {
+	case nil:
+		app.badRequestError(w, r, store.ErrDuplicateEmail)
+		return
+	case store.ErrNotFound:
+	default:
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// The plain token is mailed to the new address; only its hash is stored.
+	plainToken := rand.Text()
+
+	if err := app.store.Users.CreateEmailChange(ctx, user.ID, payload.Email, plainToken, app.config.mail.emailChangeExp); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	emailChange := struct {
+		Username   string
+		ConfirmURL string
+		ExpiresAt  time.Time
+	}{
+		Username:   user.Username,
+		ConfirmURL: app.config.frontendURL + "/confirm-email/" + plainToken,
+		ExpiresAt:  time.Now().Add(app.config.mail.emailChangeExp),
+	}
+
+	if err := app.sendEmail(ctx, mailer.EmailChangeTemplate, payload.Email, emailChange); err != nil {
+		// Nobody received the token, so it shouldn't stay usable.
+		cleanupCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+
+		if err := app.store.Users.DeleteEmailChange(cleanupCtx, plainToken); err != nil {
+			app.requestLog(ctx).Errorw("failed to delete unsent email change", "user_id", user.ID, "error", err.Error())
+		}
+
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ConfirmEmailChange godoc
+//
+//	@Summary		Confirms an email change
+//	@Description	Applies a pending email change by its confirmation token
+//	@Tags			users
+//	@Produce		json
+//	@Param			token	path		string	true	"Email change token"
+//	@Success		204		{string}	string	"Email changed"
+//	@Failure		400		{object}	error	"Email already taken"
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/users/email/{token} [put]
+func (app *application) confirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	ctx := r.Context()
+
+	user, err := app.store.Users.ConfirmEmailChange(ctx, token)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		case store.ErrDuplicateEmail:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.invalidateUser(ctx, user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteAccount godoc
+//
+//	@Summary		Deletes the authenticated user's account
+//	@Description	Deletes the account together with its posts, comments, follows and reactions
+//	@Tags			users
+//	@Produce		json
+//	@Success		204	{string}	string	"Account deleted"
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me [delete]
+func (app *application) deleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	user := getAuthUserFromCtx(r)
+	ctx := r.Context()
+
+	if err := app.store.Users.Delete(ctx, user.ID); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	app.invalidateUser(ctx, user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
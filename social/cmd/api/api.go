@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/demolaemrick/social/docs" // This is required to generate swagger docs
+	"github.com/demolaemrick/social/internal/auth"
+	"github.com/demolaemrick/social/internal/mailer"
+	"github.com/demolaemrick/social/internal/ratelimiter"
 	"github.com/demolaemrick/social/internal/store"
+	"github.com/demolaemrick/social/internal/store/cache"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -14,17 +24,103 @@ import (
 )
 
 type application struct {
-	config config
-	store  store.Storage
-	logger *zap.SugaredLogger
+	config        config
+	store         store.Storage
+	logger        *zap.SugaredLogger
+	authenticator auth.Authenticator
+	cacheStorage  cache.Storage
+	rateLimiter   ratelimiter.Limiter
+	mailer        mailer.Client
+	metrics       *httpMetrics
+	// draining is set once shutdown starts, so health checks report the
+	// instance as not ready.
+	draining atomic.Bool
 }
 
 type config struct {
-	addr    string
-	db      dbConfig
-	env     string
-	apiURL  string
-	version string
+	addr        string
+	db          dbConfig
+	env         string
+	apiURL      string
+	frontendURL string
+	version     string
+	mail        mailConfig
+	auth        authConfig
+	cache       cacheConfig
+	rateLimiter ratelimiter.Config
+	shutdown    shutdownConfig
+	search      searchConfig
+	log         logConfig
+	// metricsAddr is the internal listener for Prometheus metrics; empty
+	// disables it.
+	metricsAddr string
+}
+
+type logConfig struct {
+	// level is debug, info, warn or error; format is json or console.
+	level  string
+	format string
+}
+
+type searchConfig struct {
+	// language is the default text search configuration for new posts and
+	// comments, and for queries.
+	language string
+}
+
+type shutdownConfig struct {
+	// timeout bounds how long in-flight requests may take to finish.
+	timeout time.Duration
+	// drainDelay keeps serving while reporting draining, giving load balancers
+	// time to stop routing traffic here.
+	drainDelay time.Duration
+}
+
+type cacheConfig struct {
+	enabled  bool
+	backend  string
+	ttl      time.Duration
+	capacity int
+	redis    redisConfig
+}
+
+type redisConfig struct {
+	addr string
+	pw   string
+	db   int
+}
+
+type authConfig struct {
+	token tokenConfig
+}
+
+type tokenConfig struct {
+	secret string
+	exp    time.Duration
+	iss    string
+	aud    string
+}
+
+type mailConfig struct {
+	exp              time.Duration
+	passwordResetExp time.Duration
+	emailChangeExp   time.Duration
+	fromEmail        string
+	// backend is smtp, file or stdout.
+	backend  string
+	filePath string
+	smtp     smtpConfig
+	// maxRetries and retryDelay configure the exponential backoff on send
+	// failures.
+	maxRetries int
+	retryDelay time.Duration
+}
+
+type smtpConfig struct {
+	host     string
+	port     int
+	username string
+	password string
 }
 
 type dbConfig struct {
@@ -32,6 +128,10 @@ type dbConfig struct {
 	maxOpenConns int
 	maxIdleConns int
 	maxIdleTime  string
+	// autoMigrate applies pending migrations at startup. Replicas starting
+	// together wait on a lock for up to migrateLockTimeout.
+	autoMigrate        bool
+	migrateLockTimeout time.Duration
 }
 
 func (app *application) mount() http.Handler {
@@ -39,8 +139,10 @@ func (app *application) mount() http.Handler {
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(app.RequestLoggerMiddleware)
+	r.Use(app.MetricsMiddleware)
 	r.Use(middleware.Recoverer)
+	r.Use(app.RateLimiterMiddleware)
 
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/health", app.healthCheckHandler)
@@ -48,36 +150,82 @@ func (app *application) mount() http.Handler {
 		r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL(docsUrl)))
 
 		r.Route("/users", func(r chi.Router) {
+			r.Put("/activate/{token}", app.activateUserHandler)
+			r.Put("/email/{token}", app.confirmEmailChangeHandler)
+
+			r.Route("/me", func(r chi.Router) {
+				r.Use(app.AuthTokenMiddleware)
+
+				r.Patch("/", app.updateProfileHandler)
+				r.Put("/password", app.changePasswordHandler)
+				r.Put("/email", app.changeEmailHandler)
+				r.Delete("/", app.deleteAccountHandler)
+			})
+
 			r.Route("/{id}", func(r chi.Router) {
 				r.Use(app.usersContextMiddleware)
 
-				r.Get("/", app.getUsersHandler)
-				r.Put("/follow", app.followUserHandler)
-				r.Put("/unfollow", app.unfollowUserHandler)
+				r.With(app.OptionalAuthTokenMiddleware).Get("/", app.getUsersHandler)
+				r.Get("/followers", app.getUserFollowersHandler)
+				r.Get("/following", app.getUserFollowingHandler)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.AuthTokenMiddleware)
+
+					r.Put("/follow", app.followUserHandler)
+					r.Put("/unfollow", app.unfollowUserHandler)
+					r.Put("/block", app.blockUserHandler)
+					r.Delete("/block", app.unblockUserHandler)
+					r.Put("/mute", app.muteUserHandler)
+					r.Delete("/mute", app.unmuteUserHandler)
+				})
 			})
 
 			r.Group(func(r chi.Router) {
+				r.Use(app.AuthTokenMiddleware)
 				r.Get("/feed", app.getUserFeedHandler)
 			})
 		})
 		r.Route("/posts", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+
 			r.Post("/", app.createPostHandler)
 			r.Route("/{id}", func(r chi.Router) {
 				r.Use(app.postsContextMiddleware)
 
 				r.Get("/", app.getPostHandler)
-				r.Patch("/", app.updatePostHandler)
-				r.Delete("/", app.deletePostHandler)
+				r.Patch("/", app.checkPostOwnership("moderator", app.updatePostHandler))
+				r.Delete("/", app.checkPostOwnership("moderator", app.deletePostHandler))
 				r.Route("/comments", func(r chi.Router) {
+					r.Get("/", app.getPostCommentsHandler)
 					r.Post("/", app.createCommentHandler)
 				})
+				r.Put("/reactions/{kind}", app.addPostReactionHandler)
+				r.Delete("/reactions/{kind}", app.removePostReactionHandler)
 			})
 		})
+		r.Route("/comments", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+
+			r.Route("/{id}", func(r chi.Router) {
+				r.Use(app.commentsContextMiddleware)
+
+				r.Patch("/", app.updateCommentHandler)
+				r.Delete("/", app.checkCommentOwnership("moderator", app.deleteCommentHandler))
+				r.Get("/replies", app.getCommentRepliesHandler)
+				r.Put("/reactions/{kind}", app.addCommentReactionHandler)
+				r.Delete("/reactions/{kind}", app.removeCommentReactionHandler)
+			})
+		})
+
+		r.With(app.AuthTokenMiddleware).Get("/search", app.searchHandler)
 
 		// Public routes
 		r.Route("/authentication", func(r chi.Router) {
 			r.Post("/user", app.registerUserHandler)
-			// r.Post("/token", app.createTokenHandler)
+			r.Post("/token", app.createTokenHandler)
+			r.Post("/password-reset", app.requestPasswordResetHandler)
+			r.Put("/password-reset/{token}", app.resetPasswordHandler)
 		})
 	})
 
@@ -98,6 +246,51 @@ func (app *application) run(mux http.Handler) error {
 		IdleTimeout:  time.Minute,
 	}
 
+	var metricsSrv *http.Server
+	if app.config.metricsAddr != "" {
+		metricsSrv = app.metricsServer()
+
+		go func() {
+			app.logger.Infow("metrics server has started", "addr", app.config.metricsAddr)
+			if err := metricsSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				app.logger.Errorw("metrics server error", "error", err.Error())
+			}
+		}()
+	}
+
+	shutdown := make(chan error)
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		s := <-quit
+
+		app.draining.Store(true)
+		app.logger.Infow("signal caught, draining", "signal", s.String(), "drain_delay", app.config.shutdown.drainDelay.String())
+		time.Sleep(app.config.shutdown.drainDelay)
+
+		ctx, cancel := context.WithTimeout(context.Background(), app.config.shutdown.timeout)
+		defer cancel()
+
+		app.logger.Infow("shutting down server", "timeout", app.config.shutdown.timeout.String())
+		err := srv.Shutdown(ctx)
+		if metricsSrv != nil {
+			err = errors.Join(err, metricsSrv.Shutdown(ctx))
+		}
+		shutdown <- err
+	}()
+
 	app.logger.Infow("server has started", "addr", app.config.addr, "env", app.config.env)
-	return srv.ListenAndServe()
+
+	err := srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	if err := <-shutdown; err != nil {
+		return err
+	}
+
+	app.logger.Infow("server has stopped", "addr", app.config.addr, "env", app.config.env)
+	return nil
 }
@@ -1,6 +1,18 @@
 package main
 
-import "net/http"
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/demolaemrick/social/internal/mailer"
+	"github.com/demolaemrick/social/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+)
 
 type RegisterUserPayload struct {
 	Username string `json:"username" validate:"required,max=100"`
@@ -8,10 +20,15 @@ type RegisterUserPayload struct {
 	Password string `json:"password" validate:"required,min=3,max=72"`
 }
 
+type UserWithToken struct {
+	*store.User
+	Token string `json:"token"`
+}
+
 // registerUserHandler godoc
 //
 //	@Summary		Registers a user
-//	@Description	Registers a user
+//	@Description	Registers a user and emails them an activation link
 //	@Tags			authentication
 //	@Accept			json
 //	@Produce		json
@@ -21,5 +38,282 @@ type RegisterUserPayload struct {
 //	@Failure		500		{object}	error
 //	@Router			/authentication/user [post]
 func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
-	
+	var payload RegisterUserPayload
+
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user := &store.User{
+		Username: payload.Username,
+		Email:    payload.Email,
+	}
+
+	if err := user.Password.Set(payload.Password); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	// The plain token is handed to the user; only its hash is stored.
+	plainToken := rand.Text()
+
+	if err := app.store.Users.CreateAndInvite(ctx, user, plainToken, app.config.mail.exp); err != nil {
+		switch err {
+		case store.ErrDuplicateEmail, store.ErrDuplicateUsername:
+			app.badRequestError(w, r, err)
+		default:
+			app.storeError(w, r, err)
+		}
+		return
+	}
+
+	invitation := struct {
+		Username      string
+		ActivationURL string
+		ExpiresAt     time.Time
+	}{
+		Username:      payload.Username,
+		ActivationURL: app.config.frontendURL + "/confirm/" + plainToken,
+		ExpiresAt:     time.Now().Add(app.config.mail.exp),
+	}
+
+	// The email is sent after the user is committed so no connection is held
+	// during SMTP retries. Without the email the account can't be activated,
+	// so it is removed again, freeing the username and email for a retry.
+	if err := app.sendEmail(ctx, mailer.UserInvitationTemplate, user.Email, invitation); err != nil {
+		cleanupCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+
+		if err := app.store.Users.Delete(cleanupCtx, user.ID); err != nil {
+			app.requestLog(ctx).Errorw("failed to remove user after invitation failure", "user_id", user.ID, "error", err.Error())
+		}
+
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	userWithToken := UserWithToken{
+		User:  user,
+		Token: plainToken,
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, userWithToken); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// cleanupTimeout bounds the work done to undo a failed request.
+const cleanupTimeout = 5 * time.Second
+
+// cleanupContext returns a context for undoing a failed request that isn't
+// cancelled with it, e.g. when the client disconnects during an SMTP retry.
+func cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+}
+
+type CreateUserTokenPayload struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=3,max=72"`
+}
+
+// createTokenHandler godoc
+//
+//	@Summary		Creates a token
+//	@Description	Creates a token for a user
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		CreateUserTokenPayload	true	"User credentials"
+//	@Success		201		{string}	string					"Token"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/authentication/token [post]
+func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload CreateUserTokenPayload
+
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user, err := app.store.Users.GetByEmail(r.Context(), payload.Email)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.unauthorizedError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := user.Password.Compare(payload.Password); err != nil {
+		app.unauthorizedError(w, r, errors.New("invalid credentials"))
+		return
+	}
+
+	if !user.IsActive {
+		app.forbiddenError(w, r, "account has not been activated")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Issuer:    app.config.auth.token.iss,
+		Audience:  jwt.ClaimStrings{app.config.auth.token.aud},
+		ExpiresAt: jwt.NewNumericDate(now.Add(app.config.auth.token.exp)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+
+	token, err := app.authenticator.GenerateToken(claims)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, token); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+type PasswordResetRequestPayload struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// requestPasswordResetHandler godoc
+//
+//	@Summary		Requests a password reset
+//	@Description	Emails a one-time password reset link. The response is the same whether or not the email belongs to a user.
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		PasswordResetRequestPayload	true	"Account email"
+//	@Success		202		{string}	string						"Reset requested"
+//	@Failure		400		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/authentication/password-reset [post]
+func (app *application) requestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var payload PasswordResetRequestPayload
+
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	user, err := app.store.Users.GetByEmail(ctx, payload.Email)
+	switch err {
+	case nil:
+	case store.ErrNotFound:
+		// Don't reveal which emails have an account.
+		w.WriteHeader(http.StatusAccepted)
+		return
+	default:
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// The plain token is handed to the user; only its hash is stored.
+	plainToken := rand.Text()
+
+	// Failures past this point are only logged: the response must not differ
+	// from the one for an unknown email.
+	if err := app.store.Users.CreatePasswordReset(ctx, user.ID, plainToken, app.config.mail.passwordResetExp); err != nil {
+		app.requestLog(ctx).Errorw("failed to create password reset", "user_id", user.ID, "error", err.Error())
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	reset := struct {
+		Username  string
+		ResetURL  string
+		ExpiresAt time.Time
+	}{
+		Username:  user.Username,
+		ResetURL:  app.config.frontendURL + "/reset-password/" + plainToken,
+		ExpiresAt: time.Now().Add(app.config.mail.passwordResetExp),
+	}
+
+	if err := app.sendEmail(ctx, mailer.PasswordResetTemplate, user.Email, reset); err != nil {
+		app.requestLog(ctx).Errorw("failed to send password reset email", "user_id", user.ID, "error", err.Error())
+
+		// Nobody received the token, so it shouldn't stay usable.
+		cleanupCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+
+		if err := app.store.Users.DeletePasswordReset(cleanupCtx, plainToken); err != nil {
+			app.requestLog(ctx).Errorw("failed to delete unsent password reset", "error", err.Error())
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type ResetPasswordPayload struct {
+	Password string `json:"password" validate:"required,min=3,max=72"`
+}
+
+// resetPasswordHandler godoc
+//
+//	@Summary		Resets a password
+//	@Description	Sets a new password using a password reset token. All of the user's outstanding reset tokens are invalidated.
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	path		string					true	"Password reset token"
+//	@Param			payload	body		ResetPasswordPayload	true	"New password"
+//	@Success		204		{string}	string					"Password reset"
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/authentication/password-reset/{token} [put]
+func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	var payload ResetPasswordPayload
+
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if _, err := app.store.Users.ResetPassword(r.Context(), token, payload.Password); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -1,17 +1,44 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/demolaemrick/social/internal/store"
+	"github.com/go-chi/chi/v5"
 )
 
+type commentKey string
+
+const commentCtx commentKey = "comment"
+
 type createCommentRequest struct {
-	PostID  int64  `json:"post_id" validate:"required"`
-	UserID  int64  `json:"user_id" validate:"required"`
-	Content string `json:"content" validate:"required,max=100"`
+	Content  string `json:"content" validate:"required,max=100"`
+	ParentID *int64 `json:"parent_id" validate:"omitempty,gt=0"`
+	// Language is the text search configuration the comment is indexed with,
+	// SEARCH_LANGUAGE by default.
+	Language string `json:"language" validate:"omitempty,search_language"`
 }
 
+// CreateComment godoc
+//
+//	@Summary		Creates a comment
+//	@Description	Creates a comment on a post
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Post ID"
+//	@Param			payload	body		createCommentRequest	true	"Comment payload"
+//	@Success		201		{object}	store.Comment
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id}/comments [post]
 func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Request) {
 	var payload createCommentRequest
 	ctx := r.Context()
@@ -26,14 +53,48 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	post := getPostFromCtx(r)
+	user := getAuthUserFromCtx(r)
+
+	if payload.ParentID != nil {
+		parent, err := app.store.Comments.GetByID(ctx, *payload.ParentID)
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				app.badRequestError(w, r, errors.New("parent comment not found"))
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		if parent.PostID != post.ID {
+			app.badRequestError(w, r, errors.New("parent comment belongs to another post"))
+			return
+		}
+
+		// A comment hidden by a block is answered as if it didn't exist.
+		blocked, err := app.isBlockedFrom(ctx, user, parent.UserID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if blocked {
+			app.badRequestError(w, r, errors.New("parent comment not found"))
+			return
+		}
+	}
+
 	comment := &store.Comment{
-		PostID:  payload.PostID,
-		UserID:  payload.UserID,
-		Content: payload.Content,
+		PostID:   post.ID,
+		UserID:   user.ID,
+		ParentID: payload.ParentID,
+		Content:  payload.Content,
+		Language: cmp.Or(payload.Language, app.config.search.language),
 	}
 
 	if err := app.store.Comments.Create(ctx, comment); err != nil {
-		app.internalServerError(w, r, err)
+		app.storeError(w, r, err)
 		return
 	}
 
@@ -42,3 +103,247 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 }
+
+// GetPostComments godoc
+//
+//	@Summary		Fetches the comments of a post
+//	@Description	Fetches a page of the post's top-level comments
+//	@Tags			comments
+//	@Produce		json
+//	@Param			id		path		int		true	"Post ID"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			sort	query		string	false	"Sort by creation time, asc or desc"
+//	@Param			cursor	query		string	false	"Cursor returned as next_cursor by the previous page"
+//	@Success		200		{object}	store.CommentPage
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id}/comments [get]
+func (app *application) getPostCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+
+	fq, ok := app.parseCursorPagination(w, r, "desc")
+	if !ok {
+		return
+	}
+
+	page, err := app.store.Comments.GetByPostID(r.Context(), post.ID, getAuthUserFromCtx(r).ID, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.loadCommentReactions(r.Context(), page.Comments); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, page); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetCommentReplies godoc
+//
+//	@Summary		Fetches the replies to a comment
+//	@Description	Fetches a page of the direct replies to a comment
+//	@Tags			comments
+//	@Produce		json
+//	@Param			id		path		int		true	"Comment ID"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			sort	query		string	false	"Sort by creation time, asc or desc"
+//	@Param			cursor	query		string	false	"Cursor returned as next_cursor by the previous page"
+//	@Success		200		{object}	store.CommentPage
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/comments/{id}/replies [get]
+func (app *application) getCommentRepliesHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+
+	fq, ok := app.parseCursorPagination(w, r, "asc")
+	if !ok {
+		return
+	}
+
+	page, err := app.store.Comments.GetReplies(r.Context(), comment.ID, getAuthUserFromCtx(r).ID, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.loadCommentReactions(r.Context(), page.Comments); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, page); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// parseCursorPagination reads the page parameters, writing a 400 response and
+// returning false when they are invalid.
+func (app *application) parseCursorPagination(w http.ResponseWriter, r *http.Request, sort string) (store.Pagination, bool) {
+	fq := store.Pagination{
+		Limit: 20,
+		Sort:  sort,
+	}
+
+	fq, err := fq.ParsePagination(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return fq, false
+	}
+
+	if err := Validate.Struct(fq); err != nil {
+		app.badRequestError(w, r, err)
+		return fq, false
+	}
+
+	return fq, true
+}
+
+type updateCommentRequest struct {
+	Content string `json:"content" validate:"required,max=100"`
+}
+
+// UpdateComment godoc
+//
+//	@Summary		Updates a comment
+//	@Description	Updates a comment by ID. Only its author can edit it; the previous content is kept as a revision.
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Comment ID"
+//	@Param			payload	body		updateCommentRequest	true	"Comment payload"
+//	@Success		200		{object}	store.Comment
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		403		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/comments/{id} [patch]
+func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+	user := getAuthUserFromCtx(r)
+
+	if comment.UserID != user.ID {
+		app.forbiddenError(w, r, "only the author can edit a comment")
+		return
+	}
+
+	var payload updateCommentRequest
+
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	comment.Content = payload.Content
+
+	if err := app.store.Comments.Update(r.Context(), comment); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, comment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteComment godoc
+//
+//	@Summary		Deletes a comment
+//	@Description	Soft deletes a comment by ID, leaving a placeholder so its replies stay in the thread
+//	@Tags			comments
+//	@Produce		json
+//	@Param			id	path		int		true	"Comment ID"
+//	@Success		204	{string}	string	"Comment deleted"
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/comments/{id} [delete]
+func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+
+	if err := app.store.Comments.Delete(r.Context(), comment.ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) commentsContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idParam, 10, 64)
+
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		comment, err := app.store.Comments.GetByID(ctx, id)
+
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				app.notFoundError(w, r)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		post, err := app.store.Posts.GetByID(ctx, comment.PostID)
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				app.notFoundError(w, r)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		blocked, err := app.isBlockedFrom(ctx, getAuthUserFromCtx(r), comment.UserID, post.UserID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if blocked {
+			app.notFoundError(w, r)
+			return
+		}
+
+		ctx = context.WithValue(ctx, commentCtx, comment)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getCommentFromCtx(r *http.Request) *store.Comment {
+	comment, _ := r.Context().Value(commentCtx).(*store.Comment)
+	return comment
+}
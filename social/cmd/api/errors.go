@@ -1,25 +1,81 @@
 package main
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+
+	"github.com/demolaemrick/social/internal/store"
 )
 
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Errorw("internal server error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.requestLog(r.Context()).Errorw("internal server error", "error", err.Error())
 	writeJSONError(w, http.StatusInternalServerError, "The server encountered a problem and could not process your request")
 }
 
 func (app *application) badRequestError(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnw("bad request error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.requestLog(r.Context()).Warnw("bad request error", "error", err.Error())
 	writeJSONError(w, http.StatusBadRequest, err.Error())
 }
 
 func (app *application) notFoundError(w http.ResponseWriter, r *http.Request) {
-	app.logger.Warnw("not found error", "method", r.Method, "path", r.URL.Path)
+	app.requestLog(r.Context()).Warnw("not found error")
 	writeJSONError(w, http.StatusNotFound, "The requested resource could not be found")
 }
 
 func (app *application) conflictError(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Errorw("conflict response", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.requestLog(r.Context()).Errorw("conflict response", "error", err.Error())
 	writeJSONError(w, http.StatusConflict, err.Error())
 }
+
+func (app *application) unauthorizedError(w http.ResponseWriter, r *http.Request, err error) {
+	app.requestLog(r.Context()).Warnw("unauthorized error", "error", err.Error())
+	w.Header().Set("WWW-Authenticate", `Bearer`)
+	writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+}
+
+func (app *application) forbiddenError(w http.ResponseWriter, r *http.Request, message string) {
+	app.requestLog(r.Context()).Warnw("forbidden", "reason", message)
+	writeJSONError(w, http.StatusForbidden, message)
+}
+
+func (app *application) editConflictError(w http.ResponseWriter, r *http.Request, err error, currentVersion int) {
+	app.requestLog(r.Context()).Warnw("edit conflict", "error", err.Error(), "current_version", currentVersion)
+
+	type envelope struct {
+		Error          string `json:"error"`
+		CurrentVersion int    `json:"current_version"`
+	}
+
+	writeJSON(w, http.StatusConflict, envelope{Error: err.Error(), CurrentVersion: currentVersion})
+}
+
+func (app *application) preconditionRequiredError(w http.ResponseWriter, r *http.Request, err error) {
+	app.requestLog(r.Context()).Warnw("precondition required", "error", err.Error())
+	writeJSONError(w, http.StatusPreconditionRequired, err.Error())
+}
+
+func (app *application) rateLimitExceededError(w http.ResponseWriter, r *http.Request, retryAfter int) {
+	app.requestLog(r.Context()).Warnw("rate limit exceeded", "remote_addr", r.RemoteAddr)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded, retry after: "+strconv.Itoa(retryAfter)+"s")
+}
+
+// storeError writes the response for an error returned by a store write:
+// constraint violations become client errors, anything else a 500.
+func (app *application) storeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound), errors.Is(err, store.ErrReferenceNotFound):
+		app.notFoundError(w, r)
+	case errors.Is(err, store.ErrConflict):
+		app.conflictError(w, r, err)
+	case errors.Is(err, store.ErrSelfFollow),
+		errors.Is(err, store.ErrSelfBlock),
+		errors.Is(err, store.ErrSelfMute),
+		errors.Is(err, store.ErrCheckViolation),
+		errors.Is(err, store.ErrNotNullViolation):
+		app.badRequestError(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
@@ -13,15 +13,17 @@ import (
 //	@Tags			feed
 //	@Accept			json
 //	@Produce		json
-//	@Param			since	query		string	false	"Since"
-//	@Param			until	query		string	false	"Until"
+//	@Param			since	query		string	false	"Only posts created at or after this time (RFC3339 or YYYY-MM-DD HH:MM:SS)"
+//	@Param			until	query		string	false	"Only posts created at or before this time (RFC3339 or YYYY-MM-DD HH:MM:SS)"
 //	@Param			limit	query		int		false	"Limit"
-//	@Param			offset	query		int		false	"Offset"
+//	@Param			offset	query		int		false	"Offset, ignored when a cursor is given"
+//	@Param			cursor	query		string	false	"Cursor returned as next_cursor by the previous page"
 //	@Param			sort	query		string	false	"Sort"
 //	@Param			tags	query		string	false	"Tags"
 //	@Param			search	query		string	false	"Search"
-//	@Success		200		{object}	[]store.PostWithMetadata
+//	@Success		200		{object}	store.Feed
 //	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
 //	@Failure		500		{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/users/feed [get]
@@ -44,7 +46,9 @@ func (app *application) getUserFeedHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	feed, err := app.store.Posts.GetUserFeed(r.Context(), int64(80), fq)
+	user := getAuthUserFromCtx(r)
+
+	feed, err := app.store.Posts.GetUserFeed(r.Context(), user.ID, fq)
 
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -11,14 +11,20 @@ import (
 //	@Tags			ops
 //	@Produce		json
 //	@Success		200	{object}	string	"ok"
+//	@Failure		503	{object}	string	"draining"
 //	@Router			/health [get]
 func (app *application) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	status, code := "ok", http.StatusOK
+	if app.draining.Load() {
+		status, code = "draining", http.StatusServiceUnavailable
+	}
+
 	data := map[string]string{
-		"status":      "ok",
+		"status":      status,
 		"environment": app.config.env,
 		"version":     app.config.version,
 	}
-	if err := app.jsonResponse(w, http.StatusOK, data); err != nil {
+	if err := app.jsonResponse(w, code, data); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
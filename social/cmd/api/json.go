@@ -3,7 +3,9 @@ package main
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
 
+	"github.com/demolaemrick/social/internal/store"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -11,6 +13,10 @@ var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New(validator.WithRequiredStructEnabled())
+
+	_ = Validate.RegisterValidation("search_language", func(fl validator.FieldLevel) bool {
+		return slices.Contains(store.SearchLanguages, fl.Field().String())
+	})
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) error {
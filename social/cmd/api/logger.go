@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type loggerKey string
+
+const loggerCtx loggerKey = "logger"
+
+// requestLogger is shared by every handler in a request, so fields added on
+// the way in, like the authenticated user, also end up in the access log.
+type requestLogger struct {
+	logger *zap.SugaredLogger
+}
+
+// newLogger builds the application logger. format is json or console.
+func newLogger(cfg logConfig) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.level)
+	if err != nil {
+		return nil, err
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+
+	switch cfg.format {
+	case "json":
+	case "console":
+		zapConfig.Encoding = "console"
+		zapConfig.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.format)
+	}
+
+	return zapConfig.Build()
+}
+
+// requestLog returns the logger for the request, falling back to the
+// application logger outside of one.
+func (app *application) requestLog(ctx context.Context) *zap.SugaredLogger {
+	if rl, ok := ctx.Value(loggerCtx).(*requestLogger); ok {
+		return rl.logger
+	}
+
+	return app.logger
+}
+
+// addLogFields adds fields to the rest of the request's log lines.
+func addLogFields(ctx context.Context, fields ...any) {
+	if rl, ok := ctx.Value(loggerCtx).(*requestLogger); ok {
+		rl.logger = rl.logger.With(fields...)
+	}
+}
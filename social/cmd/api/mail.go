@@ -0,0 +1,17 @@
+package main
+
+import (
+	"context"
+
+	"github.com/demolaemrick/social/internal/mailer"
+)
+
+// sendEmail renders the named mailer template and sends it to the address.
+func (app *application) sendEmail(ctx context.Context, template string, to string, data any) error {
+	msg, err := mailer.Render(template, to, data)
+	if err != nil {
+		return err
+	}
+
+	return app.mailer.Send(ctx, msg)
+}
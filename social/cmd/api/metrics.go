@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type httpMetrics struct {
+	handler  http.Handler
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// newHTTPMetrics registers the request metrics with reg and serves everything
+// registered with it.
+func newHTTPMetrics(reg *prometheus.Registry) *httpMetrics {
+	labels := []string{"method", "route", "status"}
+
+	m := &httpMetrics{
+		handler: promhttp.HandlerFor(reg, promhttp.HandlerOpts{}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests served.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+	}
+	reg.MustRegister(m.requests, m.duration)
+
+	return m
+}
+
+// MetricsMiddleware records request counts and latencies. Requests are labelled
+// by route pattern rather than path to keep the number of series bounded.
+func (app *application) MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			route := chi.RouteContext(r.Context()).RoutePattern()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			labels := prometheus.Labels{"method": r.Method, "route": route, "status": strconv.Itoa(status)}
+			app.metrics.requests.With(labels).Inc()
+			app.metrics.duration.With(labels).Observe(time.Since(start).Seconds())
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// metricsServer serves the metrics on their own listener, so they are neither
+// public nor subject to the API's rate limits.
+func (app *application) metricsServer() *http.Server {
+	r := chi.NewRouter()
+	r.Handle("/metrics", app.metrics.handler)
+
+	return &http.Server{
+		Addr:         app.config.metricsAddr,
+		Handler:      r,
+		WriteTimeout: 30 * time.Second,
+		ReadTimeout:  10 * time.Second,
+		IdleTimeout:  time.Minute,
+	}
+}
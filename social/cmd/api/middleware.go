@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/demolaemrick/social/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestLoggerMiddleware stores a logger tagged with the request in the
+// context and writes an access log line once the response is done.
+func (app *application) RequestLoggerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		rl := &requestLogger{
+			logger: app.logger.With(
+				"request_id", middleware.GetReqID(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+			),
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ctx := context.WithValue(r.Context(), loggerCtx, rl)
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			rl.logger.Infow("request completed",
+				"route", chi.RouteContext(ctx).RoutePattern(),
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"latency", time.Since(start),
+				"remote_addr", r.RemoteAddr,
+			)
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
+
+type authKey string
+
+const authUserCtx authKey = "authUser"
+
+// AuthTokenMiddleware validates the bearer token and loads the authenticated
+// user into the request context.
+func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := app.userIDFromToken(r)
+		if err != nil {
+			app.unauthorizedError(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		user, err := app.getUser(ctx, userID)
+		if err != nil {
+			switch err {
+			case store.ErrNotFound:
+				app.unauthorizedError(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx = context.WithValue(ctx, authUserCtx, user)
+		addLogFields(ctx, "user_id", user.ID)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OptionalAuthTokenMiddleware loads the authenticated user like
+// AuthTokenMiddleware when the request carries a bearer token, and lets
+// anonymous requests through.
+func (app *application) OptionalAuthTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		app.AuthTokenMiddleware(next).ServeHTTP(w, r)
+	})
+}
+
+// userIDFromToken validates the request's bearer token and returns the ID of
+// the user it was issued to.
+func (app *application) userIDFromToken(r *http.Request) (int64, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return 0, errors.New("authorization header is missing")
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return 0, errors.New("authorization header is malformed")
+	}
+
+	jwtToken, err := app.authenticator.ValidateToken(parts[1])
+	if err != nil {
+		return 0, err
+	}
+
+	subject, err := jwtToken.Claims.GetSubject()
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(subject, 10, 64)
+}
+
+// RateLimiterMiddleware limits requests per authenticated user, or per client
+// IP for anonymous requests.
+func (app *application) RateLimiterMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.config.rateLimiter.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res := app.rateLimiter.Allow(app.rateLimitKey(r))
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+		if !res.Allowed {
+			app.rateLimitExceededError(w, r, ceilSeconds(res.RetryAfter))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitKey identifies the client. Tokens are only validated, not looked up,
+// so anonymous and authenticated requests cost no database round trip here.
+func (app *application) rateLimitKey(r *http.Request) string {
+	if userID, err := app.userIDFromToken(r); err == nil {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+
+	// middleware.RealIP has already replaced RemoteAddr when proxy headers are set.
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	return "ip:" + ip
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// getUser reads the user through the cache when it is enabled. Cache failures
+// are logged and fall back to the database.
+func (app *application) getUser(ctx context.Context, userID int64) (*store.User, error) {
+	if !app.config.cache.enabled {
+		return app.store.Users.GetByID(ctx, userID)
+	}
+
+	user, err := app.cacheStorage.Users.Get(ctx, userID)
+	if err != nil {
+		app.requestLog(ctx).Warnw("cache read failed", "cached_user_id", userID, "error", err.Error())
+	}
+
+	if user != nil {
+		return user, nil
+	}
+
+	user, err = app.store.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := app.cacheStorage.Users.Set(ctx, user); err != nil {
+		app.requestLog(ctx).Warnw("cache write failed", "cached_user_id", userID, "error", err.Error())
+	}
+
+	return user, nil
+}
+
+// invalidateUser drops a cached user after it has been changed in the store.
+func (app *application) invalidateUser(ctx context.Context, userID int64) {
+	if !app.config.cache.enabled {
+		return
+	}
+
+	if err := app.cacheStorage.Users.Delete(ctx, userID); err != nil {
+		app.requestLog(ctx).Warnw("cache invalidation failed", "cached_user_id", userID, "error", err.Error())
+	}
+}
+
+func getAuthUserFromCtx(r *http.Request) *store.User {
+	user, _ := r.Context().Value(authUserCtx).(*store.User)
+	return user
+}
+
+// checkPostOwnership lets the post's author through, or any user whose role is
+// at least as privileged as requiredRole.
+func (app *application) checkPostOwnership(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getAuthUserFromCtx(r)
+		post := getPostFromCtx(r)
+
+		app.checkOwnership(w, r, user, post.UserID, requiredRole, next)
+	}
+}
+
+// checkCommentOwnership applies the same policy as checkPostOwnership to comments.
+func (app *application) checkCommentOwnership(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getAuthUserFromCtx(r)
+		comment := getCommentFromCtx(r)
+
+		app.checkOwnership(w, r, user, comment.UserID, requiredRole, next)
+	}
+}
+
+func (app *application) checkOwnership(w http.ResponseWriter, r *http.Request, user *store.User, ownerID int64, requiredRole string, next http.HandlerFunc) {
+	if user.ID == ownerID {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	allowed, err := app.checkRolePrecedence(r.Context(), user, requiredRole)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if !allowed {
+		app.forbiddenError(w, r, "you are not allowed to modify this resource")
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
+
+func (app *application) checkRolePrecedence(ctx context.Context, user *store.User, roleName string) (bool, error) {
+	role, err := app.store.Roles.GetByName(ctx, roleName)
+	if err != nil {
+		return false, err
+	}
+
+	return user.Role.Level >= role.Level, nil
+}
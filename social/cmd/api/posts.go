@@ -1,10 +1,13 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/demolaemrick/social/internal/store"
 	"github.com/go-chi/chi/v5"
@@ -14,16 +17,23 @@ type postKey string
 
 const postCtx postKey = "post"
 
+// postCommentsPreview is how many top-level comments are embedded in a post.
+const postCommentsPreview = 10
+
 type createPostRequest struct {
 	Title   string   `json:"title" validate:"required,max=100"`
 	Content string   `json:"content" validate:"required,max=1000"`
 	Tags    []string `json:"tags"`
+	// Language is the text search configuration the post is indexed with,
+	// SEARCH_LANGUAGE by default.
+	Language string `json:"language" validate:"omitempty,search_language"`
 }
 
 type UpdatePostRequest struct {
 	Title   *string  `json:"title" validate:"omitempty,max=100"`
 	Content *string  `json:"content" validate:"omitempty,max=1000"`
 	Tags    []string `json:"tags"`
+	Version *int     `json:"version" validate:"omitempty,gte=0"`
 }
 
 // CreatePost godoc
@@ -54,15 +64,19 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	user := getAuthUserFromCtx(r)
+
 	post := &store.Post{
-		UserID:  1,
+		UserID:  user.ID,
 		Title:   payload.Title,
 		Content: payload.Content,
 		Tags:    payload.Tags,
+
+		Language: cmp.Or(payload.Language, app.config.search.language),
 	}
 
 	if err := app.store.Posts.Create(ctx, post); err != nil {
-		app.internalServerError(w, r, err)
+		app.storeError(w, r, err)
 		return
 	}
 
@@ -81,21 +95,48 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 //	@Produce		json
 //	@Param			id	path		int	true	"Post ID"
 //	@Success		200	{object}	store.Post
+//	@Header			200	{string}	ETag	"Current post version"
 //	@Failure		400	{object}	error
 //	@Failure		404	{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [get]
 func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromCtx(r)
+	ctx := r.Context()
+
+	comments, err := app.store.Comments.GetByPostID(ctx, post.ID, getAuthUserFromCtx(r).ID, store.Pagination{
+		Limit: postCommentsPreview,
+		Sort:  "desc",
+	})
+
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
-	comments, err := app.store.Comments.GetByPostID(r.Context(), post.ID)
+	total, err := app.store.Comments.CountByPostID(ctx, post.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
+	if err := app.loadCommentReactions(ctx, comments.Comments); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	reactions, err := app.store.Reactions.Counts(ctx, store.ReactionTargetPost, []int64{post.ID})
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	post.Comments = comments
+	post.Comments = comments.Comments
+	post.CommentsNextCursor = comments.NextCursor
+	post.CommentsTotal = total
+	post.ReactionCounts = reactions[post.ID]
+
+	w.Header().Set("ETag", postETag(post.Version))
 
 	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
 		app.internalServerError(w, r, err)
@@ -107,17 +148,21 @@ func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 // UpdatePost godoc
 //
 //	@Summary		Updates a post
-//	@Description	Updates a post by ID
+//	@Description	Updates a post by ID. The expected version is required, from the payload or the If-Match header; If-Match: * overwrites whatever version is current.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		int					true	"Post ID"
-//	@Param			payload	body		UpdatePostRequest	true	"Post payload"
-//	@Success		200		{object}	store.Post
-//	@Failure		400		{object}	error
-//	@Failure		401		{object}	error
-//	@Failure		404		{object}	error
-//	@Failure		500		{object}	error
+//	@Param			id			path		int					true	"Post ID"
+//	@Param			If-Match	header		string				false	"ETag of the post version being updated"
+//	@Param			payload		body		UpdatePostRequest	true	"Post payload"
+//	@Success		200			{object}	store.Post
+//	@Failure		400			{object}	error
+//	@Failure		401			{object}	error
+//	@Failure		403			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		409			{object}	error
+//	@Failure		428			{object}	error
+//	@Failure		500			{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/posts/{id} [patch]
 func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request) {
@@ -145,38 +190,73 @@ func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request
 		post.Tags = payload.Tags
 	}
 
-	if err := app.store.Posts.Update(r.Context(), post); err != nil {
-		app.internalServerError(w, r, err)
+	version, err := expectedPostVersion(r, payload.Version, post.Version)
+	if err != nil {
+		switch err {
+		case errVersionRequired:
+			app.preconditionRequiredError(w, r, err)
+		default:
+			app.badRequestError(w, r, err)
+		}
 		return
 	}
+	post.Version = version
+
+	ctx := r.Context()
+
+	if err := app.store.Posts.Update(ctx, post); err != nil {
+		switch err {
+		case store.ErrEditConflict:
+			current, getErr := app.store.Posts.GetByID(ctx, post.ID)
+			if getErr != nil {
+				app.internalServerError(w, r, getErr)
+				return
+			}
+			w.Header().Set("ETag", postETag(current.Version))
+			app.editConflictError(w, r, err, current.Version)
+		default:
+			app.storeError(w, r, err)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", postETag(post.Version))
 
 	if err := app.jsonResponse(w, http.StatusOK, post); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
 
+// DeletePost godoc
+//
+//	@Summary		Deletes a post
+//	@Description	Deletes a post by ID
+//	@Tags			posts
+//	@Produce		json
+//	@Param			id	path		int		true	"Post ID"
+//	@Success		204	{string}	string	"Post deleted"
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id} [delete]
 func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-	postID, err := strconv.ParseInt(idParam, 10, 64)
-
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
+	post := getPostFromCtx(r)
 
 	ctx := r.Context()
 
-	if err := app.store.Posts.Delete(ctx, postID); err != nil {
+	if err := app.store.Posts.Delete(ctx, post.ID); err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
 			app.notFoundError(w, r)
 		default:
 			app.internalServerError(w, r, err)
 		}
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
-
 }
 
 func (app *application) postsContextMiddleware(next http.Handler) http.Handler {
@@ -203,13 +283,86 @@ func (app *application) postsContextMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		blocked, err := app.isBlockedFrom(ctx, getAuthUserFromCtx(r), post.UserID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if blocked {
+			app.notFoundError(w, r)
+			return
+		}
+
 		ctx = context.WithValue(ctx, postCtx, post)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// isBlockedFrom reports whether a block stands between the user and any of the
+// authors. Content is hidden, not forbidden, across a block.
+func (app *application) isBlockedFrom(ctx context.Context, user *store.User, authorIDs ...int64) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	for _, authorID := range authorIDs {
+		if authorID == user.ID {
+			continue
+		}
+
+		blocked, err := app.store.Blocks.IsBlocked(ctx, user.ID, authorID)
+		if err != nil || blocked {
+			return blocked, err
+		}
+	}
+
+	return false, nil
+}
+
 func getPostFromCtx(r *http.Request) *store.Post {
 	post, _ := r.Context().Value(postCtx).(*store.Post)
 	return post
 }
+
+func postETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// errVersionRequired is returned when an update names no expected version.
+var errVersionRequired = errors.New("the expected version must be sent in the If-Match header or the payload")
+
+// expectedPostVersion resolves the version a client expects to update, from the
+// payload or an If-Match header. Without either it returns errVersionRequired,
+// unless If-Match is * to explicitly overwrite the version loaded for this
+// request.
+func expectedPostVersion(r *http.Request, payloadVersion *int, current int) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+
+	if header == "" || header == "*" {
+		switch {
+		case payloadVersion != nil:
+			return *payloadVersion, nil
+		case header == "*":
+			return current, nil
+		default:
+			return 0, errVersionRequired
+		}
+	}
+
+	tag, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid If-Match header %q", header)
+	}
+
+	version, err := strconv.Atoi(tag)
+	if err != nil {
+		return 0, fmt.Errorf("invalid If-Match header %q", header)
+	}
+
+	if payloadVersion != nil && *payloadVersion != version {
+		return 0, errors.New("version in payload does not match the If-Match header")
+	}
+
+	return version, nil
+}
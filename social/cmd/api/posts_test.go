@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExpectedPostVersion(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	const current = 7
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		payloadVersion *int
+		want           int
+		wantErr        bool
+		errIs          error
+	}{
+		{name: "header only", ifMatch: `"3"`, want: 3},
+		{name: "weak tag", ifMatch: `W/"3"`, want: 3},
+		{name: "padded header", ifMatch: ` "3" `, want: 3},
+		{name: "payload only", payloadVersion: intPtr(4), want: 4},
+		{name: "matching header and payload", ifMatch: `"5"`, payloadVersion: intPtr(5), want: 5},
+		{name: "wildcard uses current", ifMatch: "*", want: current},
+		{name: "wildcard with payload", ifMatch: "*", payloadVersion: intPtr(2), want: 2},
+		{name: "missing version", wantErr: true, errIs: errVersionRequired},
+		{name: "header and payload mismatch", ifMatch: `"5"`, payloadVersion: intPtr(6), wantErr: true},
+		{name: "unquoted header", ifMatch: "3", wantErr: true},
+		{name: "non-numeric tag", ifMatch: `"abc"`, wantErr: true},
+		{name: "weak wildcard", ifMatch: `W/*`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/v1/posts/1", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, err := expectedPostVersion(r, tt.payloadVersion, current)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expectedPostVersion() = %d, want an error", got)
+				}
+				if tt.errIs != nil && !errors.Is(err, tt.errIs) {
+					t.Fatalf("expectedPostVersion() error = %v, want %v", err, tt.errIs)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expectedPostVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expectedPostVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
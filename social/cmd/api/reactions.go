@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/demolaemrick/social/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// AddPostReaction godoc
+//
+//	@Summary		Reacts to a post
+//	@Description	Adds the authenticated user's reaction to a post
+//	@Tags			reactions
+//	@Produce		json
+//	@Param			id		path		int		true	"Post ID"
+//	@Param			kind	path		string	true	"Reaction kind: like, love, laugh, wow, sad or angry"
+//	@Success		204		{string}	string	"Reaction added"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id}/reactions/{kind} [put]
+func (app *application) addPostReactionHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	app.addReaction(w, r, store.ReactionTargetPost, post.ID)
+}
+
+// RemovePostReaction godoc
+//
+//	@Summary		Removes a reaction from a post
+//	@Description	Removes the authenticated user's reaction from a post
+//	@Tags			reactions
+//	@Produce		json
+//	@Param			id		path		int		true	"Post ID"
+//	@Param			kind	path		string	true	"Reaction kind: like, love, laugh, wow, sad or angry"
+//	@Success		204		{string}	string	"Reaction removed"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/posts/{id}/reactions/{kind} [delete]
+func (app *application) removePostReactionHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromCtx(r)
+	app.removeReaction(w, r, store.ReactionTargetPost, post.ID)
+}
+
+// AddCommentReaction godoc
+//
+//	@Summary		Reacts to a comment
+//	@Description	Adds the authenticated user's reaction to a comment
+//	@Tags			reactions
+//	@Produce		json
+//	@Param			id		path		int		true	"Comment ID"
+//	@Param			kind	path		string	true	"Reaction kind: like, love, laugh, wow, sad or angry"
+//	@Success		204		{string}	string	"Reaction added"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/comments/{id}/reactions/{kind} [put]
+func (app *application) addCommentReactionHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+
+	if comment.DeletedAt != nil {
+		app.notFoundError(w, r)
+		return
+	}
+
+	app.addReaction(w, r, store.ReactionTargetComment, comment.ID)
+}
+
+// RemoveCommentReaction godoc
+//
+//	@Summary		Removes a reaction from a comment
+//	@Description	Removes the authenticated user's reaction from a comment
+//	@Tags			reactions
+//	@Produce		json
+//	@Param			id		path		int		true	"Comment ID"
+//	@Param			kind	path		string	true	"Reaction kind: like, love, laugh, wow, sad or angry"
+//	@Success		204		{string}	string	"Reaction removed"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/comments/{id}/reactions/{kind} [delete]
+func (app *application) removeCommentReactionHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromCtx(r)
+	app.removeReaction(w, r, store.ReactionTargetComment, comment.ID)
+}
+
+func (app *application) addReaction(w http.ResponseWriter, r *http.Request, targetType string, targetID int64) {
+	reaction, err := app.reactionFromRequest(r, targetType, targetID)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.store.Reactions.Add(r.Context(), reaction); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) removeReaction(w http.ResponseWriter, r *http.Request, targetType string, targetID int64) {
+	reaction, err := app.reactionFromRequest(r, targetType, targetID)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.store.Reactions.Remove(r.Context(), reaction); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) reactionFromRequest(r *http.Request, targetType string, targetID int64) (*store.Reaction, error) {
+	kind := chi.URLParam(r, "kind")
+
+	if err := Validate.Var(kind, "oneof="+strings.Join(store.ReactionKinds, " ")); err != nil {
+		return nil, err
+	}
+
+	return &store.Reaction{
+		UserID:     getAuthUserFromCtx(r).ID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Kind:       kind,
+	}, nil
+}
+
+// loadCommentReactions fills in the reaction counts of the comments.
+func (app *application) loadCommentReactions(ctx context.Context, comments []store.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+
+	counts, err := app.store.Reactions.Counts(ctx, store.ReactionTargetComment, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range comments {
+		comments[i].ReactionCounts = counts[comments[i].ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/demolaemrick/social/internal/store"
+)
+
+// searchHandler godoc
+//
+//	@Summary		Searches posts and comments
+//	@Description	Full-text search over the posts and comments written in the requested language, ranked by relevance
+//	@Tags			search
+//	@Accept			json
+//	@Produce		json
+//	@Param			q		query		string	true	"Search terms, supports quoted phrases, OR and -exclusions"
+//	@Param			type	query		string	false	"One of all, posts or comments"
+//	@Param			lang	query		string	false	"Language of the content to search, e.g. english"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			offset	query		int		false	"Offset"
+//	@Success		200		{object}	[]store.SearchResult
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/search [get]
+func (app *application) searchHandler(w http.ResponseWriter, r *http.Request) {
+	sq := store.SearchQuery{
+		Type:     store.SearchTypeAll,
+		Language: app.config.search.language,
+		Limit:    10,
+		Offset:   0,
+		ViewerID: getAuthUserFromCtx(r).ID,
+	}
+
+	sq, err := sq.ParseSearchQuery(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(sq); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	results, err := app.store.Search.Search(r.Context(), sq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, results); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
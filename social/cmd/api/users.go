@@ -16,12 +16,12 @@ const userCtx userKey = "user"
 // GetUser godoc
 //
 //	@Summary		Fetches a user profile
-//	@Description	Fetches a user profile by ID
+//	@Description	Fetches a user profile by ID, with follower, following and post counts. is_following is set when the authenticated user follows them.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		int	true	"User ID"
-//	@Success		200	{object}	store.User
+//	@Success		200	{object}	store.UserProfile
 //	@Failure		400	{object}	error
 //	@Failure		404	{object}	error
 //	@Failure		500	{object}	error
@@ -30,13 +30,88 @@ const userCtx userKey = "user"
 func (app *application) getUsersHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromCtx(r)
 
-	if err := app.jsonResponse(w, http.StatusOK, user); err != nil {
+	var viewerID int64
+	if viewer := getAuthUserFromCtx(r); viewer != nil {
+		viewerID = viewer.ID
+	}
+
+	profile, err := app.store.Users.GetProfile(r.Context(), user, viewerID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, profile); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
 
-type loggedInUser struct {
-	UserID int64 `json:"user_id"`
+// GetUserFollowers godoc
+//
+//	@Summary		Fetches the followers of a user
+//	@Description	Fetches a page of the users following a user
+//	@Tags			users
+//	@Produce		json
+//	@Param			id		path		int		true	"User ID"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			sort	query		string	false	"Sort by follow time, asc or desc"
+//	@Param			cursor	query		string	false	"Cursor returned as next_cursor by the previous page"
+//	@Success		200		{object}	store.FollowerPage
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/users/{id}/followers [get]
+func (app *application) getUserFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	fq, ok := app.parseCursorPagination(w, r, "desc")
+	if !ok {
+		return
+	}
+
+	page, err := app.store.Followers.GetFollowers(r.Context(), user.ID, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, page); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetUserFollowing godoc
+//
+//	@Summary		Fetches the users a user follows
+//	@Description	Fetches a page of the users followed by a user
+//	@Tags			users
+//	@Produce		json
+//	@Param			id		path		int		true	"User ID"
+//	@Param			limit	query		int		false	"Limit"
+//	@Param			sort	query		string	false	"Sort by follow time, asc or desc"
+//	@Param			cursor	query		string	false	"Cursor returned as next_cursor by the previous page"
+//	@Success		200		{object}	store.FollowerPage
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/users/{id}/following [get]
+func (app *application) getUserFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromCtx(r)
+
+	fq, ok := app.parseCursorPagination(w, r, "desc")
+	if !ok {
+		return
+	}
+
+	page, err := app.store.Followers.GetFollowing(r.Context(), user.ID, fq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, page); err != nil {
+		app.internalServerError(w, r, err)
+	}
 }
 
 // FollowUser godoc
@@ -48,36 +123,35 @@ type loggedInUser struct {
 //	@Produce		json
 //	@Param			userID	path		int		true	"User ID"
 //	@Success		204		{string}	string	"User followed"
-//	@Failure		400		{object}	error	"User payload missing"
+//	@Failure		400		{object}	error	"Cannot follow yourself"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		403		{object}	error	"User blocked"
 //	@Failure		404		{object}	error	"User not found"
+//	@Failure		409		{object}	error	"User already followed"
 //	@Security		ApiKeyAuth
 //	@Router			/users/{userID}/follow [put]
 func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request) {
+	follower := getAuthUserFromCtx(r)
 	userToFollow := getUserFromCtx(r)
 
-	var payload loggedInUser
+	ctx := r.Context()
 
-	if err := readJSON(w, r, &payload); err != nil {
-		app.badRequestError(w, r, err)
+	blocked, err := app.store.Blocks.IsBlocked(ctx, follower.ID, userToFollow.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
 		return
 	}
-
-	ctx := r.Context()
-
-	if err := app.store.Followers.Follow(ctx, userToFollow.ID, payload.UserID); err != nil {
-		switch err {
-		case store.ErrConflict:
-			app.conflictError(w, r, err)
-			return
-		default:
-			app.internalServerError(w, r, err)
-			return
-		}
+	if blocked {
+		app.forbiddenError(w, r, "you cannot follow this user")
+		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
-		app.internalServerError(w, r, err)
+	if err := app.store.Followers.Follow(ctx, follower.ID, userToFollow.ID); err != nil {
+		app.storeError(w, r, err)
+		return
 	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // UnfollowUser godoc
@@ -89,30 +163,153 @@ func (app *application) followUserHandler(w http.ResponseWriter, r *http.Request
 //	@Produce		json
 //	@Param			userID	path		int		true	"User ID"
 //	@Success		204		{string}	string	"User unfollowed"
-//	@Failure		400		{object}	error	"User payload missing"
-//	@Failure		404		{object}	error	"User not found"
+//	@Failure		401		{object}	error	"Unauthorized"
+//	@Failure		404		{object}	error	"User not found or not followed"
 //	@Security		ApiKeyAuth
 //	@Router			/users/{userID}/unfollow [put]
 func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	follower := getAuthUserFromCtx(r)
 	userToUnFollow := getUserFromCtx(r)
 
-	var payload loggedInUser
+	ctx := r.Context()
+
+	if err := app.store.Followers.UnFollow(ctx, follower.ID, userToUnFollow.ID); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BlockUser godoc
+//
+//	@Summary		Blocks a user
+//	@Description	Blocks a user by ID. Blocked users can't follow each other or see each other's posts, and existing follows between them are removed.
+//	@Tags			users
+//	@Produce		json
+//	@Param			id	path		int		true	"User ID"
+//	@Success		204	{string}	string	"User blocked"
+//	@Failure		400	{object}	error	"Cannot block yourself"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"User not found"
+//	@Failure		409	{object}	error	"User already blocked"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{id}/block [put]
+func (app *application) blockUserHandler(w http.ResponseWriter, r *http.Request) {
+	blocker := getAuthUserFromCtx(r)
+	userToBlock := getUserFromCtx(r)
 
-	if err := readJSON(w, r, &payload); err != nil {
-		app.badRequestError(w, r, err)
+	if err := app.store.Blocks.Block(r.Context(), blocker.ID, userToBlock.ID); err != nil {
+		app.storeError(w, r, err)
 		return
 	}
 
-	ctx := r.Context()
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	if err := app.store.Followers.UnFollow(ctx, userToUnFollow.ID, payload.UserID); err != nil {
-		app.internalServerError(w, r, err)
+// UnblockUser godoc
+//
+//	@Summary		Unblocks a user
+//	@Description	Unblocks a user by ID
+//	@Tags			users
+//	@Produce		json
+//	@Param			id	path		int		true	"User ID"
+//	@Success		204	{string}	string	"User unblocked"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"User not found or not blocked"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{id}/block [delete]
+func (app *application) unblockUserHandler(w http.ResponseWriter, r *http.Request) {
+	blocker := getAuthUserFromCtx(r)
+	userToUnblock := getUserFromCtx(r)
+
+	if err := app.store.Blocks.Unblock(r.Context(), blocker.ID, userToUnblock.ID); err != nil {
+		app.storeError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
-		app.internalServerError(w, r, err)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MuteUser godoc
+//
+//	@Summary		Mutes a user
+//	@Description	Mutes a user by ID, hiding their posts from the feed
+//	@Tags			users
+//	@Produce		json
+//	@Param			id	path		int		true	"User ID"
+//	@Success		204	{string}	string	"User muted"
+//	@Failure		400	{object}	error	"Cannot mute yourself"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"User not found"
+//	@Failure		409	{object}	error	"User already muted"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{id}/mute [put]
+func (app *application) muteUserHandler(w http.ResponseWriter, r *http.Request) {
+	muter := getAuthUserFromCtx(r)
+	userToMute := getUserFromCtx(r)
+
+	if err := app.store.Mutes.Mute(r.Context(), muter.ID, userToMute.ID); err != nil {
+		app.storeError(w, r, err)
+		return
 	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnmuteUser godoc
+//
+//	@Summary		Unmutes a user
+//	@Description	Unmutes a user by ID
+//	@Tags			users
+//	@Produce		json
+//	@Param			id	path		int		true	"User ID"
+//	@Success		204	{string}	string	"User unmuted"
+//	@Failure		401	{object}	error	"Unauthorized"
+//	@Failure		404	{object}	error	"User not found or not muted"
+//	@Security		ApiKeyAuth
+//	@Router			/users/{id}/mute [delete]
+func (app *application) unmuteUserHandler(w http.ResponseWriter, r *http.Request) {
+	muter := getAuthUserFromCtx(r)
+	userToUnmute := getUserFromCtx(r)
+
+	if err := app.store.Mutes.Unmute(r.Context(), muter.ID, userToUnmute.ID); err != nil {
+		app.storeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ActivateUser godoc
+//
+//	@Summary		Activates a user
+//	@Description	Activates a user by invitation token
+//	@Tags			users
+//	@Produce		json
+//	@Param			token	path		string	true	"Invitation token"
+//	@Success		204		{string}	string	"User activated"
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/users/activate/{token} [put]
+func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	ctx := r.Context()
+
+	user, err := app.store.Users.Activate(ctx, token)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.invalidateUser(ctx, user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (app *application) usersContextMiddleware(next http.Handler) http.Handler {
@@ -125,7 +322,7 @@ func (app *application) usersContextMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		user, err := app.store.Users.GetByID(r.Context(), id)
+		user, err := app.getUser(r.Context(), id)
 
 		if err != nil {
 			switch err {
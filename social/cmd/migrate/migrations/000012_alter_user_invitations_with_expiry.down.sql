@@ -0,0 +1,2 @@
+ALTER TABLE
+  user_invitations DROP COLUMN expiry;
\ No newline at end of file
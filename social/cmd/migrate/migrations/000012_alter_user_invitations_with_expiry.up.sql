@@ -0,0 +1,4 @@
+ALTER TABLE
+  user_invitations
+ADD
+  COLUMN expiry TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW();
\ No newline at end of file
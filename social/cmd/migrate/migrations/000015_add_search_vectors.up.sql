@@ -0,0 +1,15 @@
+-- The dictionary is stored per row so the generated vectors stay immutable
+-- while still allowing content in other languages.
+ALTER TABLE posts ADD COLUMN language regconfig NOT NULL DEFAULT 'english';
+ALTER TABLE posts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(language, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector(language, coalesce(content, '')), 'B')
+) STORED;
+
+ALTER TABLE comments ADD COLUMN language regconfig NOT NULL DEFAULT 'english';
+ALTER TABLE comments ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector(language, coalesce(content, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN (search_vector);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_comments_parent_id;
+DROP INDEX IF EXISTS idx_comments_top_level;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_id;
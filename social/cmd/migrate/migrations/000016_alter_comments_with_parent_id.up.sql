@@ -0,0 +1,5 @@
+ALTER TABLE comments
+    ADD COLUMN parent_id BIGINT REFERENCES comments(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_top_level ON comments (post_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id, created_at, id);
@@ -0,0 +1,13 @@
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP(0) WITH TIME ZONE;
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMP(0) WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    comment_id BIGINT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_comment_id ON comment_revisions (comment_id);
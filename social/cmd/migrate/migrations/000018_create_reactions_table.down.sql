@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS trg_comments_delete_reactions ON comments;
+DROP TRIGGER IF EXISTS trg_posts_delete_reactions ON posts;
+
+DROP FUNCTION IF EXISTS delete_comment_reactions();
+DROP FUNCTION IF EXISTS delete_post_reactions();
+
+DROP TABLE IF EXISTS reactions;
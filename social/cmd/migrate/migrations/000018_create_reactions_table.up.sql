@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS reactions (
+    user_id BIGINT NOT NULL,
+    target_type VARCHAR(16) NOT NULL,
+    target_id BIGINT NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, target_type, target_id, kind),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT reactions_target_type_check CHECK (target_type IN ('post', 'comment')),
+    CONSTRAINT reactions_kind_check CHECK (kind IN ('like', 'love', 'laugh', 'wow', 'sad', 'angry'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_reactions_target ON reactions (target_type, target_id);
+
+-- Reactions point at posts and comments without a foreign key, so they are
+-- removed alongside their target by triggers instead of ON DELETE CASCADE.
+CREATE OR REPLACE FUNCTION delete_post_reactions() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM reactions WHERE target_type = 'post' AND target_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION delete_comment_reactions() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM reactions WHERE target_type = 'comment' AND target_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_posts_delete_reactions
+    AFTER DELETE ON posts
+    FOR EACH ROW EXECUTE FUNCTION delete_post_reactions();
+
+CREATE TRIGGER trg_comments_delete_reactions
+    AFTER DELETE ON comments
+    FOR EACH ROW EXECUTE FUNCTION delete_comment_reactions();
@@ -0,0 +1,4 @@
+DELETE FROM followers WHERE user_id = follower_id;
+
+ALTER TABLE followers
+    ADD CONSTRAINT followers_no_self_follow CHECK (user_id <> follower_id);
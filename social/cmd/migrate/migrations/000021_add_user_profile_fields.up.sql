@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN display_name varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN bio varchar(500) NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url text NOT NULL DEFAULT '',
+    ADD COLUMN website text NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_email_changes (
+    token bytea PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email citext NOT NULL,
+    expiry timestamp(0) with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_email_changes_user_id ON user_email_changes (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE user_invitations DROP CONSTRAINT IF EXISTS fk_user_invitations_users;
+
+ALTER TABLE posts DROP CONSTRAINT fk_posts_users;
+ALTER TABLE posts ADD CONSTRAINT fk_posts_users FOREIGN KEY (user_id) REFERENCES users(id);
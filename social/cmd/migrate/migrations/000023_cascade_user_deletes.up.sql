@@ -0,0 +1,8 @@
+-- Deleting a user removes their posts and pending invitations.
+ALTER TABLE posts DROP CONSTRAINT fk_posts_users;
+ALTER TABLE posts
+    ADD CONSTRAINT fk_posts_users FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DELETE FROM user_invitations WHERE user_id NOT IN (SELECT id FROM users);
+ALTER TABLE user_invitations
+    ADD CONSTRAINT fk_user_invitations_users FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
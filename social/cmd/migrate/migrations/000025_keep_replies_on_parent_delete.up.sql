@@ -0,0 +1,5 @@
+-- Replies outlive a hard deleted parent, e.g. when its author deletes their
+-- account, and become top-level comments.
+ALTER TABLE comments DROP CONSTRAINT comments_parent_id_fkey;
+ALTER TABLE comments
+    ADD CONSTRAINT comments_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE SET NULL;
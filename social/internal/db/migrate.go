@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies the SQL migrations in an fs.FS, tracking them in the same
+// schema_migrations table as the migrate CLI. The postgres driver holds an
+// advisory lock while migrating, so replicas running migrations at the same
+// time wait for each other instead of racing.
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+}
+
+// Migration is a migration found in the source, and whether it is applied.
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// NewMigrator reads migrations from the root of migrations and applies them
+// over a dedicated connection from db. lockTimeout bounds how long to wait for
+// another migrator to finish.
+func NewMigrator(ctx context.Context, db *sql.DB, migrations fs.FS, lockTimeout time.Duration) (*Migrator, error) {
+	src, err := iofs.New(migrations, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	// A dedicated connection, unlike postgres.WithInstance, leaves db open when
+	// the migrator is closed.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m.LockTimeout = lockTimeout
+
+	return &Migrator{m: m, source: src}, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up())
+}
+
+// Down rolls back the last n applied migrations.
+func (m *Migrator) Down(n int) error {
+	return ignoreNoChange(m.m.Steps(-n))
+}
+
+// Force sets the version without running any migration and clears the dirty
+// flag, to recover from a migration that failed halfway.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Version returns the last applied migration, 0 if there is none, and whether
+// it failed halfway.
+func (m *Migrator) Version() (uint, bool, error) {
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Migrations lists the migrations in the source in order.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	version, err := m.source.First()
+	for err == nil {
+		r, name, readErr := m.source.ReadUp(version)
+		if readErr != nil {
+			return nil, readErr
+		}
+		r.Close()
+
+		migrations = append(migrations, Migration{Version: version, Name: name, Applied: version <= current})
+		version, err = m.source.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return migrations, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// MigrateUp applies all pending migrations in migrations to db.
+func MigrateUp(ctx context.Context, db *sql.DB, migrations fs.FS, lockTimeout time.Duration) error {
+	m, err := NewMigrator(ctx, db, migrations, lockTimeout)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Up(); err != nil {
+		m.Close()
+		return err
+	}
+
+	return m.Close()
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
@@ -3,11 +3,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 	"math/rand"
+	"time"
 
-	"github.com/demolaemrick/social/internal/store"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var usernames = []string{
@@ -72,89 +74,291 @@ var comments = []string{
 	"Thanks for the information, very useful.",
 }
 
-func Seed(store store.Storage, db *sql.DB) {
-	ctx := context.Background()
+// seedPassword is the password of every seeded user.
+const seedPassword = "12345"
 
-	users := generateUsers(100)
-	// tx, _ := db.BeginTx(ctx, nil)
+type SeedConfig struct {
+	Users    int
+	Posts    int
+	Comments int
+	// Follows is the average number of users each user follows.
+	Follows int
+	// Seed makes runs reproducible: the same seed and counts generate the same
+	// rows, up to IDs and timestamps, which depend on the database and the
+	// time of the run.
+	Seed int64
+	// Truncate empties the users, posts and comments tables, and everything
+	// referencing them, and restarts their IDs first.
+	Truncate bool
+}
+
+// SeedStats counts the rows a seeding run inserted.
+type SeedStats struct {
+	Users     int
+	Posts     int
+	Comments  int
+	Followers int
+	Elapsed   time.Duration
+}
+
+type seedUser struct {
+	id        int64
+	createdAt time.Time
+}
+
+type seedPost struct {
+	id        int64
+	createdAt time.Time
+}
+
+type seedComment struct {
+	id        int64
+	createdAt time.Time
+}
+
+// Seed fills the database with generated users, posts, comments and a
+// follower graph in a single transaction, loading each table with COPY.
+func Seed(ctx context.Context, db *sql.DB, cfg SeedConfig) (*SeedStats, error) {
+	if cfg.Users < 0 || cfg.Posts < 0 || cfg.Comments < 0 || cfg.Follows < 0 {
+		return nil, errors.New("seed counts must not be negative")
+	}
+	if cfg.Posts > 0 && cfg.Users == 0 {
+		return nil, errors.New("seeding posts requires users")
+	}
+	if cfg.Comments > 0 && cfg.Posts == 0 {
+		return nil, errors.New("seeding comments requires posts")
+	}
+
+	start := time.Now()
+	rng := rand.New(rand.NewSource(cfg.Seed))
 
-	// for _, user := range users {
-	// 	if err := store.Users.Create(ctx, tx, user); err != nil {
-	// 		_ = tx.Rollback()
-	// 		log.Println("Error creating user:", err)
-	// 		return
-	// 	}
-	// }
+	// Every user gets the same password, so it is only hashed once.
+	hash, err := bcrypt.GenerateFromPassword([]byte(seedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
 
-	// tx.Commit()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	for _, user := range users {
-		if err := store.Users.Create(ctx, user); err != nil {
-			log.Println("Error creating user:", err)
-			return
+	if cfg.Truncate {
+		if _, err := tx.ExecContext(ctx, `TRUNCATE users, posts, comments RESTART IDENTITY CASCADE`); err != nil {
+			return nil, err
 		}
 	}
 
-	posts := generatePosts(200, users)
-	for _, post := range posts {
-		if err := store.Posts.Create(ctx, post); err != nil {
-			log.Println("Error creating post:", err)
-			return
+	stats := &SeedStats{}
+
+	users, err := seedUsers(ctx, tx, rng, cfg.Users, hash, start)
+	if err != nil {
+		return nil, fmt.Errorf("seeding users: %w", err)
+	}
+	stats.Users = len(users)
+
+	posts, err := seedPosts(ctx, tx, rng, cfg.Posts, users, start)
+	if err != nil {
+		return nil, fmt.Errorf("seeding posts: %w", err)
+	}
+	stats.Posts = len(posts)
+
+	stats.Comments, err = seedComments(ctx, tx, rng, cfg.Comments, users, posts, start)
+	if err != nil {
+		return nil, fmt.Errorf("seeding comments: %w", err)
+	}
+
+	stats.Followers, err = seedFollowers(ctx, tx, rng, cfg.Follows, users, start)
+	if err != nil {
+		return nil, fmt.Errorf("seeding followers: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	stats.Elapsed = time.Since(start)
+
+	return stats, nil
+}
+
+func seedUsers(ctx context.Context, tx *sql.Tx, rng *rand.Rand, num int, hash []byte, now time.Time) ([]seedUser, error) {
+	var roleID int64
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM roles WHERE name = 'user'`).Scan(&roleID); err != nil {
+		return nil, err
+	}
+
+	ids, err := reserveIDs(ctx, tx, "users", num)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]seedUser, num)
+	rows := make([][]any, num)
+	for i, id := range ids {
+		// The ID keeps names unique across runs without --truncate.
+		username := fmt.Sprintf("%s%d", usernames[rng.Intn(len(usernames))], id)
+
+		users[i] = seedUser{id: id, createdAt: randomTimeBetween(rng, now.AddDate(-1, 0, 0), now)}
+		rows[i] = []any{id, username, username + "@example.com", hash, true, roleID, users[i].createdAt}
+	}
+
+	err = copyIn(ctx, tx, "users", []string{"id", "username", "email", "password", "is_active", "role_id", "created_at"}, rows)
+	return users, err
+}
+
+func seedPosts(ctx context.Context, tx *sql.Tx, rng *rand.Rand, num int, users []seedUser, now time.Time) ([]seedPost, error) {
+	ids, err := reserveIDs(ctx, tx, "posts", num)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]seedPost, num)
+	rows := make([][]any, num)
+	for i, id := range ids {
+		author := users[rng.Intn(len(users))]
+		createdAt := randomTimeBetween(rng, author.createdAt, now)
+		postTags := []string{tags[rng.Intn(len(tags))], tags[rng.Intn(len(tags))]}
+
+		posts[i] = seedPost{id: id, createdAt: createdAt}
+		rows[i] = []any{
+			id,
+			titles[rng.Intn(len(titles))],
+			contents[rng.Intn(len(contents))],
+			author.id,
+			pq.Array(postTags),
+			createdAt,
+			createdAt,
 		}
 	}
 
-	comments := generateComments(500, users, posts)
-	for _, comment := range comments {
-		if err := store.Comments.Create(ctx, comment); err != nil {
-			log.Println("Error creating comment:", err)
-			return
+	err = copyIn(ctx, tx, "posts", []string{"id", "title", "content", "user_id", "tags", "created_at", "updated_at"}, rows)
+	return posts, err
+}
+
+// seedComments comments on random posts. About one comment in five replies to
+// an earlier comment on the same post.
+func seedComments(ctx context.Context, tx *sql.Tx, rng *rand.Rand, num int, users []seedUser, posts []seedPost, now time.Time) (int, error) {
+	ids, err := reserveIDs(ctx, tx, "comments", num)
+	if err != nil {
+		return 0, err
+	}
+
+	threads := make(map[int64][]seedComment)
+	rows := make([][]any, num)
+	for i, id := range ids {
+		post := posts[rng.Intn(len(posts))]
+		author := users[rng.Intn(len(users))]
+
+		// parentID stays a nil interface, copied as NULL, for top-level comments.
+		var parentID any
+		after := post.createdAt
+		if thread := threads[post.id]; len(thread) > 0 && rng.Intn(5) == 0 {
+			parent := thread[rng.Intn(len(thread))]
+			parentID = parent.id
+			after = parent.createdAt
+		}
+		if author.createdAt.After(after) {
+			after = author.createdAt
 		}
+
+		comment := seedComment{id: id, createdAt: randomTimeBetween(rng, after, now)}
+		threads[post.id] = append(threads[post.id], comment)
+
+		rows[i] = []any{id, post.id, author.id, parentID, comments[rng.Intn(len(comments))], comment.createdAt}
 	}
 
-	log.Println("Seeding complete")
+	err = copyIn(ctx, tx, "comments", []string{"id", "post_id", "user_id", "parent_id", "content", "created_at"}, rows)
+	return num, err
 }
 
-func generateUsers(num int) []*store.User {
-	users := make([]*store.User, num)
+// seedFollowers builds a follower graph where a few users are very popular and
+// most have a handful of followers: followed users are drawn from a Zipf
+// (power-law) distribution over a random popularity ranking.
+func seedFollowers(ctx context.Context, tx *sql.Tx, rng *rand.Rand, avgFollows int, users []seedUser, now time.Time) (int, error) {
+	if len(users) < 2 || avgFollows == 0 {
+		return 0, nil
+	}
+
+	ranking := rng.Perm(len(users))
+	zipf := rand.NewZipf(rng, 1.2, 1, uint64(len(users)-1))
 
-	for i := 0; i < num; i++ {
-		users[i] = &store.User{
-			Username: usernames[i%len(usernames)] + fmt.Sprintf("%d", i),
-			Email:    usernames[i%len(usernames)] + fmt.Sprintf("%d", i) + "@example.com",
-			Password: "12345",
+	var rows [][]any
+	for i, follower := range users {
+		// Uniform on [0, 2*avgFollows], so the mean is avgFollows.
+		want := min(rng.Intn(2*avgFollows+1), len(users)-1)
+
+		followed := map[int]bool{i: true}
+		for attempts := 0; len(followed)-1 < want && attempts < 10*want; attempts++ {
+			j := ranking[zipf.Uint64()]
+			if followed[j] {
+				continue
+			}
+			followed[j] = true
+
+			after := follower.createdAt
+			if users[j].createdAt.After(after) {
+				after = users[j].createdAt
+			}
+			rows = append(rows, []any{users[j].id, follower.id, randomTimeBetween(rng, after, now)})
 		}
 	}
 
-	return users
+	err := copyIn(ctx, tx, "followers", []string{"user_id", "follower_id", "created_at"}, rows)
+	return len(rows), err
 }
 
-func generatePosts(num int, users []*store.User) []*store.Post {
-	posts := make([]*store.Post, num)
-	for i := 0; i < num; i++ {
-		user := users[rand.Intn(len(users))]
+// reserveIDs takes num values from the table's ID sequence, so rows loaded
+// with COPY can reference each other without reading IDs back.
+func reserveIDs(ctx context.Context, tx *sql.Tx, table string, num int) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2)`,
+		table, num,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		posts[i] = &store.Post{
-			UserID:  user.ID,
-			Title:   titles[rand.Intn(len(titles))],
-			Content: titles[rand.Intn(len(contents))],
-			Tags: []string{
-				tags[rand.Intn(len(tags))],
-				tags[rand.Intn(len(tags))],
-			},
+	ids := make([]int64, 0, num)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
 		}
+		ids = append(ids, id)
 	}
 
-	return posts
+	return ids, rows.Err()
 }
 
-func generateComments(num int, users []*store.User, posts []*store.Post) []*store.Comment {
-	cms := make([]*store.Comment, num)
-	for i := 0; i < num; i++ {
-		cms[i] = &store.Comment{
-			PostID:  posts[rand.Intn(len(posts))].ID,
-			UserID:  users[rand.Intn(len(users))].ID,
-			Content: comments[rand.Intn(len(comments))],
+func copyIn(ctx context.Context, tx *sql.Tx, table string, columns []string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return err
 		}
 	}
-	return cms
+
+	// An Exec without arguments flushes the buffered rows.
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+func randomTimeBetween(rng *rand.Rand, from, to time.Time) time.Time {
+	span := to.Sub(from)
+	if span <= 0 {
+		return from
+	}
+	return from.Add(time.Duration(rng.Int63n(int64(span)))).Truncate(time.Second)
 }
@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+const (
+	UserInvitationTemplate = "user_invitation"
+	PasswordResetTemplate  = "password_reset"
+	EmailChangeTemplate    = "email_change"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Message is an email ready to be sent.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Client interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Render builds a message to the given address from the named template. Each
+// template has a text version, templates/<name>.txt, defining "subject" and
+// "body", and an HTML version, templates/<name>.html, defining "body".
+func Render(name string, to string, data any) (Message, error) {
+	msg := Message{To: to}
+
+	text, err := texttemplate.ParseFS(templatesFS, "templates/"+name+".txt")
+	if err != nil {
+		return msg, err
+	}
+
+	var buf bytes.Buffer
+	if err := text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return msg, err
+	}
+	msg.Subject = buf.String()
+
+	buf.Reset()
+	if err := text.ExecuteTemplate(&buf, "body", data); err != nil {
+		return msg, err
+	}
+	msg.Text = buf.String()
+
+	html, err := htmltemplate.ParseFS(templatesFS, "templates/"+name+".html")
+	if err != nil {
+		return msg, err
+	}
+
+	buf.Reset()
+	if err := html.ExecuteTemplate(&buf, "body", data); err != nil {
+		return msg, err
+	}
+	msg.HTML = buf.String()
+
+	return msg, nil
+}
@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		template string
+		data     any
+		url      string
+	}{
+		{
+			template: UserInvitationTemplate,
+			data: struct {
+				Username      string
+				ActivationURL string
+				ExpiresAt     time.Time
+			}{"<gopher>", "https://example.com/confirm/abc?x=1&y=2", expiresAt},
+			url: "https://example.com/confirm/abc?x=1&y=2",
+		},
+		{
+			template: PasswordResetTemplate,
+			data: struct {
+				Username  string
+				ResetURL  string
+				ExpiresAt time.Time
+			}{"<gopher>", "https://example.com/reset-password/abc", expiresAt},
+			url: "https://example.com/reset-password/abc",
+		},
+		{
+			template: EmailChangeTemplate,
+			data: struct {
+				Username   string
+				ConfirmURL string
+				ExpiresAt  time.Time
+			}{"<gopher>", "https://example.com/confirm-email/abc", expiresAt},
+			url: "https://example.com/confirm-email/abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			msg, err := Render(tt.template, "gopher@example.com", tt.data)
+			if err != nil {
+				t.Fatalf("Render error: %v", err)
+			}
+
+			if msg.To != "gopher@example.com" {
+				t.Errorf("To = %q", msg.To)
+			}
+			if msg.Subject == "" || strings.Contains(msg.Subject, "\n") {
+				t.Errorf("Subject = %q, want a single non-empty line", msg.Subject)
+			}
+			if !strings.Contains(msg.Text, tt.url) || !strings.Contains(msg.Text, "<gopher>") {
+				t.Errorf("Text is missing the URL or username:\n%s", msg.Text)
+			}
+			if !strings.Contains(msg.Text, "May 1, 2024") {
+				t.Errorf("Text is missing the expiry:\n%s", msg.Text)
+			}
+
+			// The HTML version escapes its data.
+			if strings.Contains(msg.HTML, "<gopher>") || !strings.Contains(msg.HTML, "&lt;gopher&gt;") {
+				t.Errorf("HTML doesn't escape the username:\n%s", msg.HTML)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render("missing", "gopher@example.com", nil); err == nil {
+		t.Error("Render of an unknown template succeeded")
+	}
+
+	if _, err := Render(UserInvitationTemplate, "gopher@example.com", struct{}{}); err == nil {
+		t.Error("Render with missing fields succeeded")
+	}
+}
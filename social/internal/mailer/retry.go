@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"time"
+)
+
+// RetryClient retries failed sends with exponential backoff: the n-th retry
+// waits baseDelay * 2^(n-1).
+type RetryClient struct {
+	client     Client
+	maxRetries int
+	baseDelay  time.Duration
+	after      func(time.Duration) <-chan time.Time
+}
+
+func NewRetryClient(client Client, maxRetries int, baseDelay time.Duration) *RetryClient {
+	return &RetryClient{
+		client:     client,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		after:      time.After,
+	}
+}
+
+func (c *RetryClient) Send(ctx context.Context, msg Message) error {
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if err = c.client.Send(ctx, msg); err == nil {
+			return nil
+		}
+
+		if attempt == c.maxRetries || isPermanent(err) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("sending email: %w (last error: %v)", ctx.Err(), err)
+		case <-c.after(c.baseDelay << attempt):
+		}
+	}
+
+	return fmt.Errorf("sending email: %w", err)
+}
+
+// isPermanent reports whether the SMTP server rejected the message outright,
+// in which case retrying won't help.
+func isPermanent(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code >= 500
+}
@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"slices"
+	"testing"
+	"time"
+)
+
+// flakyClient fails with the queued errors before succeeding.
+type flakyClient struct {
+	errs  []error
+	calls int
+}
+
+func (c *flakyClient) Send(ctx context.Context, msg Message) error {
+	c.calls++
+	if len(c.errs) == 0 {
+		return nil
+	}
+
+	err := c.errs[0]
+	c.errs = c.errs[1:]
+	return err
+}
+
+func TestRetryClient(t *testing.T) {
+	errTemporary := &textproto.Error{Code: 421, Msg: "try again later"}
+	errPermanent := &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	errNetwork := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		errs       []error
+		wantCalls  int
+		wantDelays []time.Duration
+		wantErr    error
+	}{
+		{
+			name:      "first attempt succeeds",
+			wantCalls: 1,
+		},
+		{
+			name:       "succeeds after retries",
+			errs:       []error{errNetwork, errTemporary},
+			wantCalls:  3,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:       "gives up after max retries",
+			errs:       []error{errNetwork, errNetwork, errNetwork, errTemporary},
+			wantCalls:  4,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+			wantErr:    errTemporary,
+		},
+		{
+			name:      "permanent failure isn't retried",
+			errs:      []error{errPermanent},
+			wantCalls: 1,
+			wantErr:   errPermanent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &flakyClient{errs: tt.errs}
+			retry := NewRetryClient(client, 3, time.Second)
+
+			var delays []time.Duration
+			retry.after = func(d time.Duration) <-chan time.Time {
+				delays = append(delays, d)
+				ch := make(chan time.Time, 1)
+				ch <- time.Time{}
+				return ch
+			}
+
+			err := retry.Send(context.Background(), Message{To: "gopher@example.com"})
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Send error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send error = %v, want it to wrap %v", err, tt.wantErr)
+			}
+			if client.calls != tt.wantCalls {
+				t.Errorf("got %d attempts, want %d", client.calls, tt.wantCalls)
+			}
+			if !slices.Equal(delays, tt.wantDelays) {
+				t.Errorf("got delays %v, want %v", delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestRetryClientStopsWhenContextIsDone(t *testing.T) {
+	client := &flakyClient{errs: []error{errors.New("connection refused")}}
+	retry := NewRetryClient(client, 3, time.Second)
+	retry.after = func(time.Duration) <-chan time.Time { return nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := retry.Send(ctx, Message{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Send error = %v, want context.Canceled", err)
+	}
+	if client.calls != 1 {
+		t.Errorf("got %d attempts, want 1", client.calls)
+	}
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// WriterClient writes emails to an io.Writer, such as stdout or a file,
+// instead of delivering them. It is meant for development and tests.
+type WriterClient struct {
+	fromEmail string
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterClient(w io.Writer, fromEmail string) *WriterClient {
+	return &WriterClient{fromEmail: fromEmail, w: w}
+}
+
+func (c *WriterClient) Send(ctx context.Context, msg Message) error {
+	data, err := msg.bytes(c.fromEmail)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.w.Write(data); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(c.w, "\r\n")
+	return err
+}
@@ -0,0 +1,71 @@
+package ratelimiter
+
+import (
+	"sync"
+	"time"
+)
+
+// FixedWindowRateLimiter allows up to limit requests per key in consecutive,
+// non-overlapping windows.
+type FixedWindowRateLimiter struct {
+	sync.Mutex
+	clients   map[string]*window
+	limit     int
+	window    time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+func NewFixedWindowLimiter(limit int, w time.Duration) *FixedWindowRateLimiter {
+	return &FixedWindowRateLimiter{
+		clients:   make(map[string]*window),
+		limit:     limit,
+		window:    w,
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (rl *FixedWindowRateLimiter) Allow(key string) Result {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	client, ok := rl.clients[key]
+	if !ok || now.Sub(client.start) >= rl.window {
+		client = &window{start: now}
+		rl.clients[key] = client
+	}
+
+	reset := client.start.Add(rl.window).Sub(now)
+
+	if client.count >= rl.limit {
+		return Result{Allowed: false, Limit: rl.limit, RetryAfter: reset, Reset: reset}
+	}
+
+	client.count++
+
+	return Result{Allowed: true, Limit: rl.limit, Remaining: rl.limit - client.count, Reset: reset}
+}
+
+// sweep drops expired windows at most once per window, so idle clients
+// don't accumulate.
+func (rl *FixedWindowRateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.window {
+		return
+	}
+
+	for key, client := range rl.clients {
+		if now.Sub(client.start) >= rl.window {
+			delete(rl.clients, key)
+		}
+	}
+	rl.lastSweep = now
+}
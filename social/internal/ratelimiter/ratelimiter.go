@@ -0,0 +1,43 @@
+package ratelimiter
+
+import "time"
+
+const (
+	StrategyFixedWindow = "fixed-window"
+	StrategyTokenBucket = "token-bucket"
+)
+
+type Limiter interface {
+	Allow(key string) Result
+}
+
+// Result describes the state of a client's quota after a call to Allow.
+type Result struct {
+	Allowed bool
+	// Limit is the number of requests allowed per time frame.
+	Limit int
+	// Remaining is the number of requests the client can still make right away.
+	Remaining int
+	// RetryAfter is how long a rejected client should wait before retrying.
+	RetryAfter time.Duration
+	// Reset is how long until the client's quota is fully restored.
+	Reset time.Duration
+}
+
+type Config struct {
+	Enabled              bool
+	Strategy             string
+	RequestsPerTimeFrame int
+	TimeFrame            time.Duration
+}
+
+// New returns the limiter for the configured strategy, defaulting to a fixed
+// window.
+func New(cfg Config) Limiter {
+	switch cfg.Strategy {
+	case StrategyTokenBucket:
+		return NewTokenBucketLimiter(cfg.RequestsPerTimeFrame, cfg.TimeFrame)
+	default:
+		return NewFixedWindowLimiter(cfg.RequestsPerTimeFrame, cfg.TimeFrame)
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// clock is a fake time source that tests move forward by hand.
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+type step struct {
+	name    string
+	advance time.Duration
+	key     string
+	want    Result
+}
+
+func runSteps(t *testing.T, c *clock, l Limiter, steps []step) {
+	t.Helper()
+
+	for _, s := range steps {
+		c.t = c.t.Add(s.advance)
+
+		if got := l.Allow(s.key); got != s.want {
+			t.Errorf("%s: Allow(%q) = %+v, want %+v", s.name, s.key, got, s.want)
+		}
+	}
+}
+
+func TestFixedWindowRateLimiter(t *testing.T) {
+	c := &clock{t: time.Unix(0, 0)}
+	rl := NewFixedWindowLimiter(3, 10*time.Second)
+	rl.now = c.now
+
+	runSteps(t, c, rl, []step{
+		{"first request", 0, "a", Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 10 * time.Second}},
+		{"second request", time.Second, "a", Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 9 * time.Second}},
+		{"last request", time.Second, "a", Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 8 * time.Second}},
+		{"over the limit", time.Second, "a", Result{Allowed: false, Limit: 3, RetryAfter: 7 * time.Second, Reset: 7 * time.Second}},
+		{"other key", 0, "b", Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 10 * time.Second}},
+		{"still over the limit", 6 * time.Second, "a", Result{Allowed: false, Limit: 3, RetryAfter: time.Second, Reset: time.Second}},
+		{"next window", time.Second, "a", Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 10 * time.Second}},
+	})
+}
+
+func TestFixedWindowRateLimiterSweep(t *testing.T) {
+	c := &clock{t: time.Unix(0, 0)}
+	rl := NewFixedWindowLimiter(3, 10*time.Second)
+	rl.lastSweep = c.t
+	rl.now = c.now
+
+	rl.Allow("idle")
+	c.t = c.t.Add(10 * time.Second)
+	rl.Allow("active")
+
+	if _, ok := rl.clients["idle"]; ok {
+		t.Error("expired window was not swept")
+	}
+	if len(rl.clients) != 1 {
+		t.Errorf("got %d clients, want 1", len(rl.clients))
+	}
+}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	c := &clock{t: time.Unix(0, 0)}
+	// One token per second, bursts of two.
+	rl := NewTokenBucketLimiter(2, 2*time.Second)
+	rl.now = c.now
+
+	runSteps(t, c, rl, []step{
+		{"first request", 0, "a", Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+		{"burst", 0, "a", Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{"empty bucket", 0, "a", Result{Allowed: false, Limit: 2, RetryAfter: time.Second, Reset: 2 * time.Second}},
+		{"half a token", 500 * time.Millisecond, "a", Result{Allowed: false, Limit: 2, RetryAfter: 500 * time.Millisecond, Reset: 1500 * time.Millisecond}},
+		{"refilled token", 500 * time.Millisecond, "a", Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{"other key", 0, "b", Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+		{"refill is capped", 10 * time.Second, "a", Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+	})
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		strategy string
+		want     string
+	}{
+		{StrategyFixedWindow, "*ratelimiter.FixedWindowRateLimiter"},
+		{StrategyTokenBucket, "*ratelimiter.TokenBucketRateLimiter"},
+		{"unknown", "*ratelimiter.FixedWindowRateLimiter"},
+	}
+
+	for _, tt := range tests {
+		l := New(Config{Strategy: tt.strategy, RequestsPerTimeFrame: 1, TimeFrame: time.Second})
+
+		if got := fmt.Sprintf("%T", l); got != tt.want {
+			t.Errorf("New(%q) = %s, want %s", tt.strategy, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package ratelimiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucketRateLimiter gives each key a bucket of limit tokens that refills
+// continuously over the time frame, allowing short bursts up to limit.
+type TokenBucketRateLimiter struct {
+	sync.Mutex
+	buckets   map[string]*bucket
+	limit     int
+	timeFrame time.Duration
+	rate      float64 // tokens per second
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucketLimiter(limit int, timeFrame time.Duration) *TokenBucketRateLimiter {
+	return &TokenBucketRateLimiter{
+		buckets:   make(map[string]*bucket),
+		limit:     limit,
+		timeFrame: timeFrame,
+		rate:      float64(limit) / timeFrame.Seconds(),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (rl *TokenBucketRateLimiter) Allow(key string) Result {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rl.limit), last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = rl.refill(b, now)
+	b.last = now
+
+	if b.tokens < 1 {
+		return Result{
+			Allowed:    false,
+			Limit:      rl.limit,
+			RetryAfter: rl.timeToTokens(1 - b.tokens),
+			Reset:      rl.timeToTokens(float64(rl.limit) - b.tokens),
+		}
+	}
+
+	b.tokens--
+
+	return Result{
+		Allowed:   true,
+		Limit:     rl.limit,
+		Remaining: int(math.Floor(b.tokens)),
+		Reset:     rl.timeToTokens(float64(rl.limit) - b.tokens),
+	}
+}
+
+func (rl *TokenBucketRateLimiter) refill(b *bucket, now time.Time) float64 {
+	return math.Min(float64(rl.limit), b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+}
+
+func (rl *TokenBucketRateLimiter) timeToTokens(tokens float64) time.Duration {
+	return time.Duration(tokens / rl.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, as they are
+// indistinguishable from new ones.
+func (rl *TokenBucketRateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.timeFrame {
+		return
+	}
+
+	for key, b := range rl.buckets {
+		if rl.refill(b, now) >= float64(rl.limit) {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
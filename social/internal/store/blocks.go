@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type BlockStore struct {
+	db DBTX
+}
+
+// Block records that blockerID blocked blockedID and removes any follow
+// relationship between them, in either direction.
+func (s *BlockStore) Block(ctx context.Context, blockerID int64, blockedID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `INSERT INTO blocks (blocker_id, blocked_id) VALUES ($1, $2)`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+			err = translateError(err)
+			if violatedConstraint(err) == "blocks_no_self_block" {
+				return ErrSelfBlock
+			}
+			return err
+		}
+
+		query = `
+			DELETE FROM followers
+			WHERE (user_id = $1 AND follower_id = $2) OR (user_id = $2 AND follower_id = $1)
+		`
+
+		_, err := tx.ExecContext(ctx, query, blockerID, blockedID)
+		return err
+	})
+}
+
+func (s *BlockStore) Unblock(ctx context.Context, blockerID int64, blockedID int64) error {
+	query := `DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// IsBlocked reports whether either user has blocked the other.
+func (s *BlockStore) IsBlocked(ctx context.Context, userID int64, otherID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var blocked bool
+	err := s.db.QueryRowContext(ctx, query, userID, otherID).Scan(&blocked)
+	return blocked, err
+}
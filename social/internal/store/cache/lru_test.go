@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/demolaemrick/social/internal/store"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRU[string, int](2, time.Minute)
+
+	c.set("a", 1)
+	c.set("b", 2)
+	c.get("a") // b is now the least recently used
+	c.set("c", 3)
+
+	tests := []struct {
+		key    string
+		want   int
+		wantOK bool
+	}{
+		{"a", 1, true},
+		{"b", 0, false},
+		{"c", 3, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := c.get(tt.key)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("get(%q) = %d, %t, want %d, %t", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestLRUSetOverwrites(t *testing.T) {
+	c := newLRU[string, int](2, time.Minute)
+
+	c.set("a", 1)
+	c.set("b", 2)
+	c.set("a", 10) // updates a and makes b the least recently used
+	c.set("c", 3)
+
+	if got, ok := c.get("a"); !ok || got != 10 {
+		t.Errorf("get(a) = %d, %t, want 10, true", got, ok)
+	}
+	if _, ok := c.get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if c.order.Len() != len(c.items) {
+		t.Errorf("list has %d entries, map has %d", c.order.Len(), len(c.items))
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newLRU[string, int](0, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.set("a", 1)
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("entry expired at its TTL, want it kept until after")
+	}
+
+	now = now.Add(time.Nanosecond)
+	if _, ok := c.get("a"); ok {
+		t.Fatal("entry outlived its TTL")
+	}
+	if len(c.items) != 0 || c.order.Len() != 0 {
+		t.Error("expired entry was not removed")
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	c := newLRU[string, int](0, time.Minute)
+
+	c.set("a", 1)
+	c.delete("a")
+	c.delete("missing")
+
+	if _, ok := c.get("a"); ok {
+		t.Error("deleted entry is still cached")
+	}
+}
+
+func TestLRUZeroCapacityIsUnbounded(t *testing.T) {
+	c := newLRU[int, int](0, time.Minute)
+
+	for i := range 100 {
+		c.set(i, i)
+	}
+
+	if c.order.Len() != 100 {
+		t.Errorf("got %d entries, want 100", c.order.Len())
+	}
+}
+
+func TestMemoryUserStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStorage(10, time.Minute).Users
+
+	user, err := s.Get(ctx, 1)
+	if user != nil || err != nil {
+		t.Fatalf("Get on a miss = %v, %v, want nil, nil", user, err)
+	}
+
+	if err := s.Set(ctx, &store.User{ID: 1, Username: "gopher"}); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err = s.Get(ctx, 1)
+	if err != nil || user == nil || user.Username != "gopher" {
+		t.Fatalf("Get = %v, %v, want the cached user", user, err)
+	}
+
+	// Callers get a copy and can't change the cached entry.
+	user.Username = "changed"
+	if user, _ := s.Get(ctx, 1); user.Username != "gopher" {
+		t.Errorf("cached username = %q, want gopher", user.Username)
+	}
+
+	if err := s.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := s.Get(ctx, 1); user != nil {
+		t.Error("deleted user is still cached")
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/demolaemrick/social/internal/store"
+	"github.com/redis/go-redis/v9"
+)
+
+// Storage caches hot store lookups. A Get miss returns a nil value and a nil
+// error, so callers fall back to the database.
+type Storage struct {
+	Users interface {
+		Get(context.Context, int64) (*store.User, error)
+		Set(context.Context, *store.User) error
+		Delete(context.Context, int64) error
+	}
+}
+
+func NewRedisStorage(rdb *redis.Client, ttl time.Duration) Storage {
+	return Storage{
+		Users: &RedisUserStore{rdb: rdb, ttl: ttl},
+	}
+}
+
+func NewMemoryStorage(capacity int, ttl time.Duration) Storage {
+	return Storage{
+		Users: &MemoryUserStore{lru: newLRU[int64, store.User](capacity, ttl)},
+	}
+}
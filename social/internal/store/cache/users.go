@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/demolaemrick/social/internal/store"
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisUserStore struct {
+	rdb *redis.Client
+	ttl time.Duration
+}
+
+func (s *RedisUserStore) Get(ctx context.Context, userID int64) (*store.User, error) {
+	data, err := s.rdb.Get(ctx, userCacheKey(userID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var user store.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (s *RedisUserStore) Set(ctx context.Context, user *store.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	return s.rdb.SetEx(ctx, userCacheKey(user.ID), data, s.ttl).Err()
+}
+
+func (s *RedisUserStore) Delete(ctx context.Context, userID int64) error {
+	return s.rdb.Del(ctx, userCacheKey(userID)).Err()
+}
+
+func userCacheKey(userID int64) string {
+	return fmt.Sprintf("user-%d", userID)
+}
+
+type MemoryUserStore struct {
+	lru *lru[int64, store.User]
+}
+
+// Get returns a copy of the cached user, so callers can't mutate cache entries.
+func (s *MemoryUserStore) Get(ctx context.Context, userID int64) (*store.User, error) {
+	user, ok := s.lru.get(userID)
+	if !ok {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+func (s *MemoryUserStore) Set(ctx context.Context, user *store.User) error {
+	s.lru.set(user.ID, *user)
+	return nil
+}
+
+func (s *MemoryUserStore) Delete(ctx context.Context, userID int64) error {
+	s.lru.delete(userID)
+	return nil
+}
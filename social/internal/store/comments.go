@@ -3,66 +3,240 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 )
 
 type Comment struct {
-	ID        int64  `json:"id"`
-	PostID    int64  `json:"post_id"`
-	UserID    int64  `json:"user_id"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
-	User      User   `json:"user"`
+	ID         int64   `json:"id"`
+	PostID     int64   `json:"post_id"`
+	UserID     int64   `json:"user_id"`
+	ParentID   *int64  `json:"parent_id"`
+	Content    string  `json:"content"`
+	ReplyCount int     `json:"reply_count"`
+	CreatedAt  string  `json:"created_at"`
+	EditedAt   *string `json:"edited_at"`
+	DeletedAt  *string `json:"deleted_at"`
+	User       User    `json:"user"`
+
+	// Language is the text search configuration the comment is indexed with.
+	Language       string         `json:"language,omitempty"`
+	ReactionCounts map[string]int `json:"reaction_counts"`
+}
+
+// deletedCommentContent replaces the body of soft deleted comments, so replies
+// keep their place in the thread.
+const deletedCommentContent = "[deleted]"
+
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"next_cursor,omitempty"`
 }
 
 type CommentStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 func (s *CommentStore) Create(ctx context.Context, comment *Comment) error {
 	query := `
-		INSERT INTO comments (post_id, user_id, content)
-		VALUES ($1, $2, $3)
+		INSERT INTO comments (post_id, user_id, parent_id, content, language)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err := s.db.QueryRowContext(ctx, query, comment.PostID, comment.UserID, comment.Content).Scan(
+	err := s.db.QueryRowContext(ctx, query, comment.PostID, comment.UserID, comment.ParentID, comment.Content, comment.Language).Scan(
 		&comment.ID,
 		&comment.CreatedAt,
 	)
 
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
-func (s *CommentStore) GetByPostID(ctx context.Context, postID int64) ([]Comment, error) {
+
+func (s *CommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, parent_id, content, created_at, edited_at, deleted_at, language
+		FROM comments
+		WHERE id = $1
+	`
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var comment Comment
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.ParentID,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.DeletedAt,
+		&comment.Language,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &comment, nil
+}
+
+// Update replaces the comment's content, keeping the previous one as a
+// revision. Deleted comments can't be edited and yield ErrNotFound.
+func (s *CommentStore) Update(ctx context.Context, comment *Comment) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.createRevision(ctx, tx, comment.ID); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE comments
+			SET content = $1, edited_at = now()
+			WHERE id = $2 AND deleted_at IS NULL
+			RETURNING edited_at
+		`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, comment.Content, comment.ID).Scan(&comment.EditedAt)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return translateError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete soft deletes the comment: its content is kept as a revision and
+// replaced with a placeholder.
+func (s *CommentStore) Delete(ctx context.Context, id int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.createRevision(ctx, tx, id); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE comments
+			SET content = $1, deleted_at = now()
+			WHERE id = $2 AND deleted_at IS NULL
+		`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, deletedCommentContent, id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// createRevision records the current content of a live comment.
+func (s *CommentStore) createRevision(ctx context.Context, tx *sql.Tx, commentID int64) error {
+	query := `
+		INSERT INTO comment_revisions (comment_id, content)
+		SELECT id, content FROM comments WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, commentID)
+	return err
+}
+
+// GetByPostID returns a page of the post's top-level comments that viewerID
+// can see.
+func (s *CommentStore) GetByPostID(ctx context.Context, postID, viewerID int64, pagination Pagination) (*CommentPage, error) {
+	return s.list(ctx, `c.post_id = $1 AND c.parent_id IS NULL`, postID, viewerID, pagination)
+}
+
+// GetReplies returns a page of the direct replies to a comment that viewerID
+// can see.
+func (s *CommentStore) GetReplies(ctx context.Context, parentID, viewerID int64, pagination Pagination) (*CommentPage, error) {
+	return s.list(ctx, `c.parent_id = $1`, parentID, viewerID, pagination)
+}
+
+// CountByPostID returns the number of comments on a post, replies included.
+func (s *CommentStore) CountByPostID(ctx context.Context, postID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE post_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRowContext(ctx, query, postID).Scan(&count)
+	return count, err
+}
+
+// list pages through the comments matching filter, a constant condition on $1.
+// Comments by users in a block with viewerID are left out.
+func (s *CommentStore) list(ctx context.Context, filter string, id, viewerID int64, pagination Pagination) (*CommentPage, error) {
+	direction, comparison := pagination.orderBy()
+
 	query := `SELECT 
 			c.id,
 			c.post_id,
 			c.user_id,
+			c.parent_id,
 			c.content,
 			c.created_at,
+			c.edited_at,
+			c.deleted_at,
 			u.id,
-			u.username
+			u.username,
+			(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id) AS reply_count
 		FROM comments c 
 		JOIN users u ON c.user_id = u.id 
-		WHERE c.post_id = $1 
-		ORDER BY c.created_at DESC
+		WHERE ` + filter + ` AND
+			($3::timestamptz IS NULL OR (c.created_at, c.id) ` + comparison + ` ($3::timestamptz, $4::bigint)) AND
+			NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.blocker_id = $5 AND b.blocked_id = c.user_id) OR
+					(b.blocked_id = $5 AND b.blocker_id = c.user_id)
+			)
+		ORDER BY c.created_at ` + direction + `, c.id ` + direction + `
+		LIMIT $2
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, postID)
+	cursorTime, cursorID := pagination.cursorArgs()
+
+	rows, err := s.db.QueryContext(ctx, query, id, pagination.fetchLimit(), cursorTime, cursorID, viewerID)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 
-	comments := []Comment{}
+	page := &CommentPage{Comments: []Comment{}}
 	for rows.Next() {
 		var comment Comment
 		comment.User = User{}
@@ -70,15 +244,30 @@ func (s *CommentStore) GetByPostID(ctx context.Context, postID int64) ([]Comment
 			&comment.ID,
 			&comment.PostID,
 			&comment.UserID,
+			&comment.ParentID,
 			&comment.Content,
 			&comment.CreatedAt,
+			&comment.EditedAt,
+			&comment.DeletedAt,
 			&comment.User.ID,
 			&comment.User.Username,
+			&comment.ReplyCount,
 		)
 		if err != nil {
 			return nil, err
 		}
-		comments = append(comments, comment)
+		page.Comments = append(page.Comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page.Comments, page.NextCursor, err = trimPage(page.Comments, pagination.Limit, func(comment Comment) (string, int64) {
+		return comment.CreatedAt, comment.ID
+	})
+	if err != nil {
+		return nil, err
 	}
-	return comments, nil
+
+	return page, nil
 }
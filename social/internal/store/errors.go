@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ConstraintError is a constraint violation reported by Postgres. It wraps one
+// of the constraint sentinels, so callers match it with errors.Is, and keeps
+// the violated constraint for stores that map it to a more specific error.
+// Its message is the sentinel's, so it is safe to show to clients.
+type ConstraintError struct {
+	Err        error
+	Constraint string
+	cause      *pq.Error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Err, e.cause}
+}
+
+// translateError converts Postgres integrity constraint violations into a
+// *ConstraintError. Any other error is returned unchanged.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	var sentinel error
+	switch pqErr.Code {
+	case "23505":
+		sentinel = ErrConflict
+	case "23503":
+		sentinel = ErrReferenceNotFound
+	case "23514":
+		sentinel = ErrCheckViolation
+	case "23502":
+		sentinel = ErrNotNullViolation
+	default:
+		return err
+	}
+
+	return &ConstraintError{Err: sentinel, Constraint: pqErr.Constraint, cause: pqErr}
+}
+
+// violatedConstraint returns the name of the constraint err violated, if any.
+func violatedConstraint(err error) string {
+	var cErr *ConstraintError
+	if errors.As(err, &cErr) {
+		return cErr.Constraint
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		want           error
+		wantConstraint string
+	}{
+		{"unique violation", &pq.Error{Code: "23505", Constraint: "users_email_key"}, ErrConflict, "users_email_key"},
+		{"foreign key violation", &pq.Error{Code: "23503", Constraint: "fk_comments_post_id"}, ErrReferenceNotFound, "fk_comments_post_id"},
+		{"check violation", &pq.Error{Code: "23514", Constraint: "followers_no_self_follow"}, ErrCheckViolation, "followers_no_self_follow"},
+		{"not null violation", &pq.Error{Code: "23502"}, ErrNotNullViolation, ""},
+		{"wrapped violation", fmt.Errorf("insert: %w", &pq.Error{Code: "23505", Constraint: "users_username_key"}), ErrConflict, "users_username_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translateError(tt.err)
+
+			if !errors.Is(got, tt.want) {
+				t.Errorf("translateError() = %v, want it to match %v", got, tt.want)
+			}
+			if got.Error() != tt.want.Error() {
+				t.Errorf("message = %q, want the sentinel's %q", got.Error(), tt.want.Error())
+			}
+			if c := violatedConstraint(got); c != tt.wantConstraint {
+				t.Errorf("violatedConstraint() = %q, want %q", c, tt.wantConstraint)
+			}
+
+			var pqErr *pq.Error
+			if !errors.As(got, &pqErr) {
+				t.Error("the *pq.Error cause is not reachable")
+			}
+		})
+	}
+}
+
+func TestTranslateErrorPassesThrough(t *testing.T) {
+	deadlock := &pq.Error{Code: "40P01"}
+
+	tests := []error{
+		nil,
+		sql.ErrNoRows,
+		errors.New("connection reset"),
+		deadlock,
+	}
+
+	for _, err := range tests {
+		if got := translateError(err); got != err {
+			t.Errorf("translateError(%v) = %v, want it unchanged", err, got)
+		}
+		if c := violatedConstraint(err); c != "" {
+			t.Errorf("violatedConstraint(%v) = %q, want none", err, c)
+		}
+	}
+}
@@ -1,20 +1,23 @@
 package store
 
-import (
-	"context"
-	"database/sql"
-
-	"github.com/lib/pq"
-)
+import "context"
 
 type Follower struct {
 	UserID     int64  `json:"user_id"`
 	FollowerID int64  `json:"follower_id"`
 	CreatedAt  string `json:"created_at"`
+	// User is the other side of the relationship: the follower in a followers
+	// list, the followed user in a following list.
+	User User `json:"user"`
+}
+
+type FollowerPage struct {
+	Followers  []Follower `json:"followers"`
+	NextCursor string     `json:"next_cursor,omitempty"`
 }
 
 type FollowerStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 func (s *FollowerStore) Follow(ctx context.Context, followerID int64, userID int64) error {
@@ -27,8 +30,9 @@ func (s *FollowerStore) Follow(ctx context.Context, followerID int64, userID int
 
 	_, err := s.db.ExecContext(ctx, query, userID, followerID)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return ErrConflict
+		err = translateError(err)
+		if violatedConstraint(err) == "followers_no_self_follow" {
+			return ErrSelfFollow
 		}
 	}
 	return err
@@ -43,7 +47,88 @@ func (s *FollowerStore) UnFollow(ctx context.Context, followerID int64, userID i
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := s.db.ExecContext(ctx, query, userID, followerID)
-	return err
+	res, err := s.db.ExecContext(ctx, query, userID, followerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+
+}
+
+// GetFollowers returns a page of the users following userID.
+func (s *FollowerStore) GetFollowers(ctx context.Context, userID int64, pagination Pagination) (*FollowerPage, error) {
+	return s.list(ctx, "f.user_id", "f.follower_id", userID, pagination)
+}
+
+// GetFollowing returns a page of the users followed by userID.
+func (s *FollowerStore) GetFollowing(ctx context.Context, userID int64, pagination Pagination) (*FollowerPage, error) {
+	return s.list(ctx, "f.follower_id", "f.user_id", userID, pagination)
+}
+
+// list pages through the followers rows whose column is id, joining the user
+// in other. Rows are ordered by (created_at, other), which is unique for a
+// given id. column and other are only ever the constants above.
+func (s *FollowerStore) list(ctx context.Context, column, other string, id int64, pagination Pagination) (*FollowerPage, error) {
+	direction, comparison := pagination.orderBy()
+
+	query := `
+		SELECT f.user_id, f.follower_id, f.created_at, u.id, u.username, u.created_at
+		FROM followers f
+		JOIN users u ON u.id = ` + other + `
+		WHERE ` + column + ` = $1 AND
+			($3::timestamptz IS NULL OR (f.created_at, ` + other + `) ` + comparison + ` ($3::timestamptz, $4::bigint))
+		ORDER BY f.created_at ` + direction + `, ` + other + ` ` + direction + `
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	cursorTime, cursorID := pagination.cursorArgs()
+
+	rows, err := s.db.QueryContext(ctx, query, id, pagination.fetchLimit(), cursorTime, cursorID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	page := &FollowerPage{Followers: []Follower{}}
+	for rows.Next() {
+		var f Follower
+		err := rows.Scan(
+			&f.UserID,
+			&f.FollowerID,
+			&f.CreatedAt,
+			&f.User.ID,
+			&f.User.Username,
+			&f.User.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		page.Followers = append(page.Followers, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page.Followers, page.NextCursor, err = trimPage(page.Followers, pagination.Limit, func(f Follower) (string, int64) {
+		return f.CreatedAt, f.User.ID
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	return page, nil
 }
@@ -0,0 +1,293 @@
+// Package metrics instruments the store with Prometheus metrics.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/demolaemrick/social/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Instrument registers the store query duration histogram with reg and returns
+// a decorator that wraps the stores of a store.Storage to record every call in it.
+func Instrument(reg prometheus.Registerer) func(store.Storage) store.Storage {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "store_query_duration_seconds",
+		Help:    "Duration of store method calls.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"store", "method"})
+	reg.MustRegister(duration)
+
+	return func(s store.Storage) store.Storage {
+		s.Posts = posts{s.Posts, observer{duration, "posts"}}
+		s.Users = users{s.Users, observer{duration, "users"}}
+		s.Comments = comments{s.Comments, observer{duration, "comments"}}
+		s.Followers = followers{s.Followers, observer{duration, "followers"}}
+		s.Blocks = blocks{s.Blocks, observer{duration, "blocks"}}
+		s.Mutes = mutes{s.Mutes, observer{duration, "mutes"}}
+		s.Roles = roles{s.Roles, observer{duration, "roles"}}
+		s.Search = search{s.Search, observer{duration, "search"}}
+		s.Reactions = reactions{s.Reactions, observer{duration, "reactions"}}
+		return s
+	}
+}
+
+type observer struct {
+	duration *prometheus.HistogramVec
+	store    string
+}
+
+func (o observer) observe(method string, start time.Time) {
+	o.duration.WithLabelValues(o.store, method).Observe(time.Since(start).Seconds())
+}
+
+type posts struct {
+	next store.PostRepository
+	observer
+}
+
+func (s posts) Create(ctx context.Context, post *store.Post) error {
+	defer s.observe("Create", time.Now())
+	return s.next.Create(ctx, post)
+}
+
+func (s posts) GetByID(ctx context.Context, id int64) (*store.Post, error) {
+	defer s.observe("GetByID", time.Now())
+	return s.next.GetByID(ctx, id)
+}
+
+func (s posts) Update(ctx context.Context, post *store.Post) error {
+	defer s.observe("Update", time.Now())
+	return s.next.Update(ctx, post)
+}
+
+func (s posts) Delete(ctx context.Context, id int64) error {
+	defer s.observe("Delete", time.Now())
+	return s.next.Delete(ctx, id)
+}
+
+func (s posts) GetUserFeed(ctx context.Context, userID int64, pagination store.Pagination) (*store.Feed, error) {
+	defer s.observe("GetUserFeed", time.Now())
+	return s.next.GetUserFeed(ctx, userID, pagination)
+}
+
+type users struct {
+	next store.UserRepository
+	observer
+}
+
+func (s users) Create(ctx context.Context, user *store.User) error {
+	defer s.observe("Create", time.Now())
+	return s.next.Create(ctx, user)
+}
+
+func (s users) GetByID(ctx context.Context, id int64) (*store.User, error) {
+	defer s.observe("GetByID", time.Now())
+	return s.next.GetByID(ctx, id)
+}
+
+func (s users) GetByEmail(ctx context.Context, email string) (*store.User, error) {
+	defer s.observe("GetByEmail", time.Now())
+	return s.next.GetByEmail(ctx, email)
+}
+
+func (s users) CreateAndInvite(ctx context.Context, user *store.User, token string, exp time.Duration) error {
+	defer s.observe("CreateAndInvite", time.Now())
+	return s.next.CreateAndInvite(ctx, user, token, exp)
+}
+
+func (s users) Activate(ctx context.Context, token string) (*store.User, error) {
+	defer s.observe("Activate", time.Now())
+	return s.next.Activate(ctx, token)
+}
+
+func (s users) GetProfile(ctx context.Context, user *store.User, viewerID int64) (*store.UserProfile, error) {
+	defer s.observe("GetProfile", time.Now())
+	return s.next.GetProfile(ctx, user, viewerID)
+}
+
+func (s users) UpdateProfile(ctx context.Context, user *store.User) error {
+	defer s.observe("UpdateProfile", time.Now())
+	return s.next.UpdateProfile(ctx, user)
+}
+
+func (s users) UpdatePassword(ctx context.Context, user *store.User) error {
+	defer s.observe("UpdatePassword", time.Now())
+	return s.next.UpdatePassword(ctx, user)
+}
+
+func (s users) CreateEmailChange(ctx context.Context, userID int64, email string, token string, exp time.Duration) error {
+	defer s.observe("CreateEmailChange", time.Now())
+	return s.next.CreateEmailChange(ctx, userID, email, token, exp)
+}
+
+func (s users) ConfirmEmailChange(ctx context.Context, token string) (*store.User, error) {
+	defer s.observe("ConfirmEmailChange", time.Now())
+	return s.next.ConfirmEmailChange(ctx, token)
+}
+
+func (s users) DeleteEmailChange(ctx context.Context, token string) error {
+	defer s.observe("DeleteEmailChange", time.Now())
+	return s.next.DeleteEmailChange(ctx, token)
+}
+
+func (s users) Delete(ctx context.Context, id int64) error {
+	defer s.observe("Delete", time.Now())
+	return s.next.Delete(ctx, id)
+}
+
+func (s users) CreatePasswordReset(ctx context.Context, userID int64, token string, exp time.Duration) error {
+	defer s.observe("CreatePasswordReset", time.Now())
+	return s.next.CreatePasswordReset(ctx, userID, token, exp)
+}
+
+func (s users) DeletePasswordReset(ctx context.Context, token string) error {
+	defer s.observe("DeletePasswordReset", time.Now())
+	return s.next.DeletePasswordReset(ctx, token)
+}
+
+func (s users) ResetPassword(ctx context.Context, token string, password string) (*store.User, error) {
+	defer s.observe("ResetPassword", time.Now())
+	return s.next.ResetPassword(ctx, token, password)
+}
+
+type comments struct {
+	next store.CommentRepository
+	observer
+}
+
+func (s comments) Create(ctx context.Context, comment *store.Comment) error {
+	defer s.observe("Create", time.Now())
+	return s.next.Create(ctx, comment)
+}
+
+func (s comments) GetByID(ctx context.Context, id int64) (*store.Comment, error) {
+	defer s.observe("GetByID", time.Now())
+	return s.next.GetByID(ctx, id)
+}
+
+func (s comments) GetByPostID(ctx context.Context, postID, viewerID int64, pagination store.Pagination) (*store.CommentPage, error) {
+	defer s.observe("GetByPostID", time.Now())
+	return s.next.GetByPostID(ctx, postID, viewerID, pagination)
+}
+
+func (s comments) GetReplies(ctx context.Context, parentID, viewerID int64, pagination store.Pagination) (*store.CommentPage, error) {
+	defer s.observe("GetReplies", time.Now())
+	return s.next.GetReplies(ctx, parentID, viewerID, pagination)
+}
+
+func (s comments) CountByPostID(ctx context.Context, postID int64) (int, error) {
+	defer s.observe("CountByPostID", time.Now())
+	return s.next.CountByPostID(ctx, postID)
+}
+
+func (s comments) Update(ctx context.Context, comment *store.Comment) error {
+	defer s.observe("Update", time.Now())
+	return s.next.Update(ctx, comment)
+}
+
+func (s comments) Delete(ctx context.Context, id int64) error {
+	defer s.observe("Delete", time.Now())
+	return s.next.Delete(ctx, id)
+}
+
+type followers struct {
+	next store.FollowerRepository
+	observer
+}
+
+func (s followers) Follow(ctx context.Context, followerID int64, userID int64) error {
+	defer s.observe("Follow", time.Now())
+	return s.next.Follow(ctx, followerID, userID)
+}
+
+func (s followers) UnFollow(ctx context.Context, followerID int64, userID int64) error {
+	defer s.observe("UnFollow", time.Now())
+	return s.next.UnFollow(ctx, followerID, userID)
+}
+
+func (s followers) GetFollowers(ctx context.Context, userID int64, pagination store.Pagination) (*store.FollowerPage, error) {
+	defer s.observe("GetFollowers", time.Now())
+	return s.next.GetFollowers(ctx, userID, pagination)
+}
+
+func (s followers) GetFollowing(ctx context.Context, userID int64, pagination store.Pagination) (*store.FollowerPage, error) {
+	defer s.observe("GetFollowing", time.Now())
+	return s.next.GetFollowing(ctx, userID, pagination)
+}
+
+type blocks struct {
+	next store.BlockRepository
+	observer
+}
+
+func (s blocks) Block(ctx context.Context, blockerID int64, blockedID int64) error {
+	defer s.observe("Block", time.Now())
+	return s.next.Block(ctx, blockerID, blockedID)
+}
+
+func (s blocks) Unblock(ctx context.Context, blockerID int64, blockedID int64) error {
+	defer s.observe("Unblock", time.Now())
+	return s.next.Unblock(ctx, blockerID, blockedID)
+}
+
+func (s blocks) IsBlocked(ctx context.Context, userID int64, otherID int64) (bool, error) {
+	defer s.observe("IsBlocked", time.Now())
+	return s.next.IsBlocked(ctx, userID, otherID)
+}
+
+type mutes struct {
+	next store.MuteRepository
+	observer
+}
+
+func (s mutes) Mute(ctx context.Context, muterID int64, mutedID int64) error {
+	defer s.observe("Mute", time.Now())
+	return s.next.Mute(ctx, muterID, mutedID)
+}
+
+func (s mutes) Unmute(ctx context.Context, muterID int64, mutedID int64) error {
+	defer s.observe("Unmute", time.Now())
+	return s.next.Unmute(ctx, muterID, mutedID)
+}
+
+type roles struct {
+	next store.RoleRepository
+	observer
+}
+
+func (s roles) GetByName(ctx context.Context, name string) (*store.Role, error) {
+	defer s.observe("GetByName", time.Now())
+	return s.next.GetByName(ctx, name)
+}
+
+type search struct {
+	next store.SearchRepository
+	observer
+}
+
+func (s search) Search(ctx context.Context, query store.SearchQuery) ([]store.SearchResult, error) {
+	defer s.observe("Search", time.Now())
+	return s.next.Search(ctx, query)
+}
+
+type reactions struct {
+	next store.ReactionRepository
+	observer
+}
+
+func (s reactions) Add(ctx context.Context, reaction *store.Reaction) error {
+	defer s.observe("Add", time.Now())
+	return s.next.Add(ctx, reaction)
+}
+
+func (s reactions) Remove(ctx context.Context, reaction *store.Reaction) error {
+	defer s.observe("Remove", time.Now())
+	return s.next.Remove(ctx, reaction)
+}
+
+func (s reactions) Counts(ctx context.Context, targetType string, targetIDs []int64) (map[int64]map[string]int, error) {
+	defer s.observe("Counts", time.Now())
+	return s.next.Counts(ctx, targetType, targetIDs)
+}
@@ -0,0 +1,46 @@
+package store
+
+import "context"
+
+type MuteStore struct {
+	db DBTX
+}
+
+func (s *MuteStore) Mute(ctx context.Context, muterID int64, mutedID int64) error {
+	query := `INSERT INTO mutes (muter_id, muted_id) VALUES ($1, $2)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, muterID, mutedID)
+	if err != nil {
+		err = translateError(err)
+		if violatedConstraint(err) == "mutes_no_self_mute" {
+			return ErrSelfMute
+		}
+	}
+	return err
+}
+
+func (s *MuteStore) Unmute(ctx context.Context, muterID int64, mutedID int64) error {
+	query := `DELETE FROM mutes WHERE muter_id = $1 AND muted_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, muterID, mutedID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -1,20 +1,113 @@
 package store
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type Pagination struct {
-	Limit  int      `json:"limit" validate:"gte=1,lte=20"`
-	Offset int      `json:"offset" validate:"gte=0"`
-	Sort   string   `json:"sort" validate:"oneof=asc desc"`
-	Tags   []string `json:"tags" validate:"max=5"`
-	Search string   `json:"search" validate:"max=100"`
-	Since  string   `json:"since"`
-	Until  string   `json:"until"`
+	Limit  int        `json:"limit" validate:"gte=1,lte=20"`
+	Offset int        `json:"offset" validate:"gte=0"`
+	Sort   string     `json:"sort" validate:"oneof=asc desc"`
+	Tags   []string   `json:"tags" validate:"max=5"`
+	Search string     `json:"search" validate:"max=100"`
+	Since  *time.Time `json:"since"`
+	Until  *time.Time `json:"until"`
+	// Cursor, when set, takes precedence over Offset.
+	Cursor *Cursor `json:"cursor"`
+}
+
+// Cursor is the position of the last item of a page in a list ordered by
+// (created_at, id). Clients receive it as an opaque string.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"id"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// nextCursor returns the cursor following the last item, if a page is full.
+func nextCursor(hasMore bool, createdAt string, id int64) (string, error) {
+	if !hasMore {
+		return "", nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return "", err
+	}
+
+	return Cursor{CreatedAt: t, ID: id}.Encode(), nil
+}
+
+// fetchLimit is the number of rows to query for a page of q. The one extra row
+// tells trimPage whether there is a next page.
+func (q Pagination) fetchLimit() int {
+	return q.Limit + 1
+}
+
+// trimPage cuts items, fetched with fetchLimit, down to a page of limit and
+// returns the cursor following it, if there is a next page. key returns the
+// creation time and ID an item is ordered by.
+func trimPage[T any](items []T, limit int, key func(T) (string, int64)) ([]T, string, error) {
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	if len(items) == 0 {
+		return items, "", nil
+	}
+
+	createdAt, id := key(items[len(items)-1])
+	cursor, err := nextCursor(hasMore, createdAt, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return items, cursor, nil
+}
+
+// orderBy returns the ORDER BY direction and the keyset comparison operator for
+// q.Sort. Only these constants are ever interpolated into SQL.
+func (q Pagination) orderBy() (string, string) {
+	if q.Sort == "asc" {
+		return "ASC", ">"
+	}
+	return "DESC", "<"
+}
+
+// cursorArgs returns the keyset query arguments, NULL when there is no cursor.
+func (q Pagination) cursorArgs() (any, any) {
+	if q.Cursor == nil {
+		return nil, nil
+	}
+	return q.Cursor.CreatedAt, q.Cursor.ID
 }
 
 func (q Pagination) ParsePagination(r *http.Request) (Pagination, error) {
@@ -25,7 +118,7 @@ func (q Pagination) ParsePagination(r *http.Request) (Pagination, error) {
 	if limit != "" {
 		l, err := strconv.Atoi(limit)
 		if err != nil {
-			return q, nil
+			return q, fmt.Errorf("invalid limit: %w", err)
 		}
 		q.Limit = l
 	}
@@ -33,7 +126,7 @@ func (q Pagination) ParsePagination(r *http.Request) (Pagination, error) {
 	if offset != "" {
 		o, err := strconv.Atoi(offset)
 		if err != nil {
-			return q, nil
+			return q, fmt.Errorf("invalid offset: %w", err)
 		}
 		q.Offset = o
 	}
@@ -56,22 +149,54 @@ func (q Pagination) ParsePagination(r *http.Request) (Pagination, error) {
 
 	since := queryParams.Get("since")
 	if since != "" {
-		q.Since = parseTime(since)
+		t, err := parseTime(since)
+		if err != nil {
+			return q, fmt.Errorf("invalid since: %w", err)
+		}
+		q.Since = &t
 	}
 
 	until := queryParams.Get("until")
 	if until != "" {
-		q.Until = parseTime(until)
+		t, err := parseTime(until)
+		if err != nil {
+			return q, fmt.Errorf("invalid until: %w", err)
+		}
+		q.Until = &t
+	}
+
+	if q.Since != nil && q.Until != nil && q.Since.After(*q.Until) {
+		return q, errors.New("since must not be after until")
+	}
+
+	cursor := queryParams.Get("cursor")
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return q, err
+		}
+		q.Cursor = c
+		q.Offset = 0
 	}
 
 	return q, nil
 }
 
-func parseTime(s string) string {
-	t, err := time.Parse(time.DateTime, s)
-	if err != nil {
-		return ""
+// parseTime accepts RFC3339 timestamps, or time.DateTime ones read as UTC.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateTime} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
 	}
 
-	return t.Format(time.DateTime)
+	return time.Time{}, fmt.Errorf("%q is not an RFC3339 or %q timestamp", s, time.DateTime)
+}
+
+// timeArg returns t as a query argument, NULL when unset.
+func timeArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
 }
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []Cursor{
+		{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), ID: 42},
+		{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC), ID: 1},
+		{CreatedAt: time.Date(1999, 12, 31, 23, 59, 59, 0, time.FixedZone("", 2*60*60)), ID: 1 << 62},
+	}
+
+	for _, want := range tests {
+		got, err := DecodeCursor(want.Encode())
+		if err != nil {
+			t.Fatalf("DecodeCursor(%+v.Encode()) error: %v", want, err)
+		}
+
+		if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+			t.Errorf("round trip = %+v, want %+v", *got, want)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not json", "bm90IGpzb24"},
+		{"missing time", Cursor{ID: 1}.Encode()},
+		{"wrong types", "eyJ0IjoxLCJpZCI6IngifQ"}, // {"t":1,"id":"x"}
+	}
+
+	for _, tt := range tests {
+		if _, err := DecodeCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: DecodeCursor(%q) error = %v, want ErrInvalidCursor", tt.name, tt.cursor, err)
+		}
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	cursor, err := nextCursor(false, "2024-05-01T12:30:00Z", 7)
+	if err != nil || cursor != "" {
+		t.Errorf("last page: nextCursor = %q, %v, want no cursor", cursor, err)
+	}
+
+	cursor, err = nextCursor(true, "2024-05-01T12:30:00.5Z", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 500_000_000, time.UTC), ID: 7}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("nextCursor decodes to %+v, want %+v", *got, want)
+	}
+}
+
+func TestTrimPage(t *testing.T) {
+	type row struct {
+		createdAt string
+		id        int64
+	}
+	key := func(r row) (string, int64) { return r.createdAt, r.id }
+
+	rows := []row{
+		{"2024-05-01T12:30:00Z", 3},
+		{"2024-05-01T12:29:00Z", 2},
+		{"2024-05-01T12:28:00Z", 1},
+	}
+
+	page, cursor, err := trimPage(rows, 3, key)
+	if err != nil || len(page) != 3 || cursor != "" {
+		t.Errorf("last page: trimPage = %v, %q, %v, want all rows and no cursor", page, cursor, err)
+	}
+
+	page, cursor, err = trimPage(rows, 2, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(page, rows[:2]) {
+		t.Errorf("trimPage kept %v, want %v", page, rows[:2])
+	}
+
+	got, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 2 || !got.CreatedAt.Equal(time.Date(2024, 5, 1, 12, 29, 0, 0, time.UTC)) {
+		t.Errorf("cursor decodes to %+v, want the last kept row", *got)
+	}
+
+	page, cursor, err = trimPage([]row{}, 2, key)
+	if err != nil || len(page) != 0 || cursor != "" {
+		t.Errorf("empty page: trimPage = %v, %q, %v, want no rows and no cursor", page, cursor, err)
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	defaults := Pagination{Limit: 20, Sort: "desc"}
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    Pagination
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  Pagination{Limit: 20, Sort: "desc", Tags: []string{}},
+		},
+		{
+			name:  "limit, offset and sort",
+			query: "limit=5&offset=10&sort=asc",
+			want:  Pagination{Limit: 5, Offset: 10, Sort: "asc", Tags: []string{}},
+		},
+		{
+			name:  "tags and search",
+			query: "tags=go,sql&search=pagination",
+			want:  Pagination{Limit: 20, Sort: "desc", Tags: []string{"go", "sql"}, Search: "pagination"},
+		},
+		{
+			name:  "RFC3339 bounds",
+			query: "since=2024-05-01T00:00:00Z&until=2024-05-02T14:00:00%2B02:00",
+			want:  Pagination{Limit: 20, Sort: "desc", Tags: []string{}, Since: &since, Until: &until},
+		},
+		{
+			name:  "DateTime bounds",
+			query: "since=2024-05-01+00:00:00&until=2024-05-02+12:00:00",
+			want:  Pagination{Limit: 20, Sort: "desc", Tags: []string{}, Since: &since, Until: &until},
+		},
+		{
+			name:  "equal bounds",
+			query: "since=2024-05-01T00:00:00Z&until=2024-05-01T00:00:00Z",
+			want:  Pagination{Limit: 20, Sort: "desc", Tags: []string{}, Since: &since, Until: &since},
+		},
+		{
+			name:  "cursor resets offset",
+			query: "offset=10&cursor=" + Cursor{CreatedAt: since, ID: 3}.Encode(),
+			want:  Pagination{Limit: 20, Sort: "desc", Tags: []string{}, Cursor: &Cursor{CreatedAt: since, ID: 3}},
+		},
+		{name: "malformed limit", query: "limit=ten", wantErr: true},
+		{name: "malformed offset", query: "offset=-x", wantErr: true},
+		{name: "malformed since", query: "since=yesterday", wantErr: true},
+		{name: "date only until", query: "until=2024-05-01", wantErr: true},
+		{name: "since after until", query: "since=2024-05-02T00:00:00Z&until=2024-05-01T00:00:00Z", wantErr: true},
+		{name: "malformed cursor", query: "cursor=nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+
+			got, err := defaults.ParsePagination(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParsePagination(%q) = %+v, want an error", tt.query, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePagination(%q) error: %v", tt.query, err)
+			}
+
+			if !equalPagination(got, tt.want) {
+				t.Errorf("ParsePagination(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginationValidation(t *testing.T) {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+
+	tests := []struct {
+		name    string
+		p       Pagination
+		wantErr bool
+	}{
+		{"valid", Pagination{Limit: 20, Sort: "asc"}, false},
+		{"zero limit", Pagination{Limit: 0, Sort: "asc"}, true},
+		{"limit too high", Pagination{Limit: 21, Sort: "asc"}, true},
+		{"negative offset", Pagination{Limit: 1, Offset: -1, Sort: "asc"}, true},
+		{"unknown sort", Pagination{Limit: 1, Sort: "up"}, true},
+		{"too many tags", Pagination{Limit: 1, Sort: "asc", Tags: []string{"a", "b", "c", "d", "e", "f"}}, true},
+	}
+
+	for _, tt := range tests {
+		if err := validate.Struct(tt.p); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate = %v, want error %t", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func equalPagination(a, b Pagination) bool {
+	equalTime := func(x, y *time.Time) bool {
+		return x == nil && y == nil || x != nil && y != nil && x.Equal(*y)
+	}
+	equalCursor := func(x, y *Cursor) bool {
+		return x == nil && y == nil || x != nil && y != nil && x.ID == y.ID && x.CreatedAt.Equal(y.CreatedAt)
+	}
+
+	return a.Limit == b.Limit &&
+		a.Offset == b.Offset &&
+		a.Sort == b.Sort &&
+		slices.Equal(a.Tags, b.Tags) &&
+		a.Search == b.Search &&
+		equalTime(a.Since, b.Since) &&
+		equalTime(a.Until, b.Until) &&
+		equalCursor(a.Cursor, b.Cursor)
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 
 	"github.com/lib/pq"
@@ -19,40 +20,54 @@ type Post struct {
 	Version   int       `json:"version"`
 	CreatedAt string    `json:"created_at"`
 	UpdatedAt string    `json:"updated_at"`
+
+	// CommentsTotal counts all the post's comments, while Comments only holds
+	// the first page of top-level ones.
+	CommentsTotal      int    `json:"comments_total"`
+	CommentsNextCursor string `json:"comments_next_cursor,omitempty"`
+
+	// Language is the text search configuration the post is indexed with.
+	Language       string         `json:"language,omitempty"`
+	ReactionCounts map[string]int `json:"reaction_counts"`
 }
 
 type PostWithMetadata struct {
 	Post
 	CommentCount int `json:"comment_count"`
 }
+
+type Feed struct {
+	Posts      []PostWithMetadata `json:"posts"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
 type PostStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 func (s *PostStore) Create(ctx context.Context, post *Post) error {
-	query := `INSERT INTO posts (content, title, user_id, tags) 
-		VALUES ($1, $2, $3, $4) 
+	query := `INSERT INTO posts (content, title, user_id, tags, language) 
+		VALUES ($1, $2, $3, $4, $5) 
 		RETURNING id, created_at, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err := s.db.QueryRowContext(ctx, query, post.Content, post.Title, post.UserID, pq.Array(post.Tags)).Scan(
+	err := s.db.QueryRowContext(ctx, query, post.Content, post.Title, post.UserID, pq.Array(post.Tags), post.Language).Scan(
 		&post.ID,
 		&post.CreatedAt,
 		&post.UpdatedAt,
 	)
 
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	return nil
 }
 
 func (s *PostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
-	query := `SELECT id, content, title, user_id, tags, version, created_at, updated_at 
+	query := `SELECT id, content, title, user_id, tags, version, created_at, updated_at, language 
 		FROM posts 
 		WHERE id = $1
 		LIMIT 1
@@ -70,6 +85,7 @@ func (s *PostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
 		&post.Version,
 		&post.CreatedAt,
 		&post.UpdatedAt,
+		&post.Language,
 	)
 
 	if err != nil {
@@ -85,6 +101,8 @@ func (s *PostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
 	return &post, nil
 }
 
+// Update applies the changes only if the post is still at post.Version, and
+// returns ErrEditConflict when another request updated it first.
 func (s *PostStore) Update(ctx context.Context, post *Post) error {
 	query := `
         UPDATE posts
@@ -105,19 +123,36 @@ func (s *PostStore) Update(ctx context.Context, post *Post) error {
 		post.Content,
 		pq.Array(post.Tags),
 		post.ID,
+		post.Version,
 	).Scan(&post.Content, &post.Title, pq.Array(&post.Tags), &post.Version, &post.UpdatedAt)
 
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return ErrNotFound
+			return s.conflictOrNotFound(ctx, post.ID)
 		default:
-			return err
+			return translateError(err)
 		}
 	}
 	return nil
 }
 
+// conflictOrNotFound tells apart an update that matched no row because the post
+// is gone from one that lost an optimistic concurrency race.
+func (s *PostStore) conflictOrNotFound(ctx context.Context, id int64) error {
+	query := `SELECT EXISTS (SELECT 1 FROM posts WHERE id = $1)`
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrEditConflict
+	}
+	return ErrNotFound
+}
+
 func (s *PostStore) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM posts WHERE id = $1`
 
@@ -140,36 +175,70 @@ func (s *PostStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (s *PostStore) GetUserFeed(ctx context.Context, userID int64, pagination Pagination) ([]PostWithMetadata, error) {
+// GetUserFeed returns the posts of the user and of the users they follow,
+// leaving out muted accounts and blocks in either direction. Pages are keyed on
+// (created_at, id), so concurrent inserts don't shift them.
+func (s *PostStore) GetUserFeed(ctx context.Context, userID int64, pagination Pagination) (*Feed, error) {
+	direction, comparison := pagination.orderBy()
+
 	query := `
 		SELECT 
 			p.id, p.content, p.title, p.user_id, p.tags, p.version, p.created_at, u.username,
-			COUNT(c.id) AS comment_count
+			COUNT(c.id) AS comment_count,
+			COALESCE((
+				SELECT jsonb_object_agg(rc.kind, rc.count)
+				FROM (
+					SELECT r.kind, COUNT(*) AS count
+					FROM reactions r
+					WHERE r.target_type = 'post' AND r.target_id = p.id
+					GROUP BY r.kind
+				) rc
+			), '{}') AS reaction_counts
 		FROM posts p
 		LEFT JOIN comments c ON c.post_id = p.id
 		LEFT JOIN users u ON u.id = p.user_id
-		JOIN followers f ON f.follower_id = p.user_id OR f.follower_id = $1
 		WHERE 
-			f.user_id = $1 AND
+			(p.user_id = $1 OR p.user_id IN (SELECT user_id FROM followers WHERE follower_id = $1)) AND
+			p.user_id NOT IN (SELECT muted_id FROM mutes WHERE muter_id = $1) AND
+			NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.blocker_id = $1 AND b.blocked_id = p.user_id) OR (b.blocker_id = p.user_id AND b.blocked_id = $1)
+			) AND
 			(p.title ILIKE '%' || $4 || '%' OR p.content ILIKE '%' || $4 || '%') AND
-			(p.tags @> $5 OR $5 = '{}')		
+			(p.tags @> $5 OR $5 = '{}') AND
+			($6::timestamptz IS NULL OR (p.created_at, p.id) ` + comparison + ` ($6::timestamptz, $7::bigint)) AND
+			($8::timestamptz IS NULL OR p.created_at >= $8::timestamptz) AND
+			($9::timestamptz IS NULL OR p.created_at <= $9::timestamptz)
 		GROUP BY p.id, u.username
-		ORDER BY p.created_at ` + pagination.Sort + `
+		ORDER BY p.created_at ` + direction + `, p.id ` + direction + `
 		LIMIT $2 OFFSET $3
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, userID, pagination.Limit, pagination.Offset, pagination.Search, pq.Array(pagination.Tags))
+	cursorTime, cursorID := pagination.cursorArgs()
+
+	rows, err := s.db.QueryContext(ctx, query,
+		userID,
+		pagination.fetchLimit(),
+		pagination.Offset,
+		pagination.Search,
+		pq.Array(pagination.Tags),
+		cursorTime,
+		cursorID,
+		timeArg(pagination.Since),
+		timeArg(pagination.Until),
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	feed := []PostWithMetadata{}
+	feed := &Feed{Posts: []PostWithMetadata{}}
 
 	for rows.Next() {
 		var post PostWithMetadata
+		var reactionCounts []byte
 		err := rows.Scan(
 			&post.ID,
 			&post.Content,
@@ -180,11 +249,26 @@ func (s *PostStore) GetUserFeed(ctx context.Context, userID int64, pagination Pa
 			&post.CreatedAt,
 			&post.User.Username,
 			&post.CommentCount,
+			&reactionCounts,
 		)
 		if err != nil {
 			return nil, err
 		}
-		feed = append(feed, post)
+		if err := json.Unmarshal(reactionCounts, &post.ReactionCounts); err != nil {
+			return nil, err
+		}
+		feed.Posts = append(feed.Posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	feed.Posts, feed.NextCursor, err = trimPage(feed.Posts, pagination.Limit, func(post PostWithMetadata) (string, int64) {
+		return post.CreatedAt, post.ID
+	})
+	if err != nil {
+		return nil, err
 	}
+
 	return feed, nil
 }
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// ReactionKinds lists the accepted reactions, matching reactions_kind_check.
+var ReactionKinds = []string{"like", "love", "laugh", "wow", "sad", "angry"}
+
+type Reaction struct {
+	UserID     int64  `json:"user_id"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	Kind       string `json:"kind"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type ReactionStore struct {
+	db DBTX
+}
+
+// Add records the reaction. Reacting twice with the same kind is a no-op.
+func (s *ReactionStore) Add(ctx context.Context, reaction *Reaction) error {
+	query := `
+		INSERT INTO reactions (user_id, target_type, target_id, kind)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, reaction.UserID, reaction.TargetType, reaction.TargetID, reaction.Kind)
+	return translateError(err)
+}
+
+func (s *ReactionStore) Remove(ctx context.Context, reaction *Reaction) error {
+	query := `
+		DELETE FROM reactions
+		WHERE user_id = $1 AND target_type = $2 AND target_id = $3 AND kind = $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, reaction.UserID, reaction.TargetType, reaction.TargetID, reaction.Kind)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Counts returns the number of reactions of each kind on each of the targets.
+// Every target is in the result, with an empty map if it has no reactions.
+func (s *ReactionStore) Counts(ctx context.Context, targetType string, targetIDs []int64) (map[int64]map[string]int, error) {
+	query := `
+		SELECT target_id, kind, COUNT(*)
+		FROM reactions
+		WHERE target_type = $1 AND target_id = ANY($2)
+		GROUP BY target_id, kind
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, targetType, pq.Array(targetIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]map[string]int, len(targetIDs))
+	for _, id := range targetIDs {
+		counts[id] = map[string]int{}
+	}
+
+	for rows.Next() {
+		var targetID int64
+		var kind string
+		var count int
+		if err := rows.Scan(&targetID, &kind, &count); err != nil {
+			return nil, err
+		}
+		counts[targetID][kind] = count
+	}
+
+	return counts, rows.Err()
+}
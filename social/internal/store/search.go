@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// SearchLanguages are the text search configurations posts and comments can be
+// written and searched in.
+var SearchLanguages = []string{
+	"simple", "arabic", "danish", "dutch", "english", "finnish", "french", "german",
+	"hungarian", "indonesian", "irish", "italian", "lithuanian", "nepali", "norwegian",
+	"portuguese", "romanian", "russian", "spanish", "swedish", "tamil", "turkish",
+}
+
+const (
+	SearchTypeAll      = "all"
+	SearchTypePosts    = "posts"
+	SearchTypeComments = "comments"
+)
+
+type SearchQuery struct {
+	Query    string `json:"q" validate:"required,max=100"`
+	Type     string `json:"type" validate:"oneof=all posts comments"`
+	Language string `json:"lang" validate:"search_language"`
+	Limit    int    `json:"limit" validate:"gte=1,lte=50"`
+	Offset   int    `json:"offset" validate:"gte=0"`
+	// ViewerID is the searching user; content involving a block between them
+	// and its author is left out.
+	ViewerID int64 `json:"-"`
+}
+
+func (q SearchQuery) ParseSearchQuery(r *http.Request) (SearchQuery, error) {
+	queryParams := r.URL.Query()
+
+	q.Query = queryParams.Get("q")
+
+	if t := queryParams.Get("type"); t != "" {
+		q.Type = t
+	}
+
+	if lang := queryParams.Get("lang"); lang != "" {
+		q.Language = lang
+	}
+
+	if limit := queryParams.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return q, fmt.Errorf("invalid limit: %w", err)
+		}
+		q.Limit = l
+	}
+
+	if offset := queryParams.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			return q, fmt.Errorf("invalid offset: %w", err)
+		}
+		q.Offset = o
+	}
+
+	return q, nil
+}
+
+type SearchResult struct {
+	Type      string  `json:"type"`
+	ID        int64   `json:"id"`
+	PostID    int64   `json:"post_id"`
+	UserID    int64   `json:"user_id"`
+	Title     string  `json:"title,omitempty"`
+	Snippet   string  `json:"snippet"`
+	Rank      float64 `json:"rank"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type SearchStore struct {
+	db DBTX
+}
+
+// Search runs a web-style full-text query against the posts and comments
+// written in the query's language and returns the matches ranked by relevance,
+// with the matched terms highlighted.
+func (s *SearchStore) Search(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	// Snippets are only built for the requested page, since ts_headline has to
+	// reparse the whole document.
+	query := `
+		WITH search AS (SELECT websearch_to_tsquery($1::regconfig, $2) AS q),
+		page AS (
+			SELECT
+				'post' AS type, p.id, p.id AS post_id, p.user_id, p.title::text AS title, p.content,
+				ts_rank(p.search_vector, search.q) AS rank,
+				p.created_at
+			FROM posts p, search
+			WHERE $3 IN ('all', 'posts') AND p.language = $1::regconfig AND p.search_vector @@ search.q AND NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.blocker_id = $6 AND b.blocked_id = p.user_id) OR (b.blocker_id = p.user_id AND b.blocked_id = $6)
+			)
+			UNION ALL
+			SELECT
+				'comment' AS type, c.id, c.post_id, c.user_id, '' AS title, c.content,
+				ts_rank(c.search_vector, search.q) AS rank,
+				c.created_at
+			FROM comments c
+			JOIN posts p ON p.id = c.post_id, search
+			WHERE $3 IN ('all', 'comments') AND c.deleted_at IS NULL AND c.language = $1::regconfig AND c.search_vector @@ search.q AND NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.blocker_id = $6 AND b.blocked_id IN (c.user_id, p.user_id)) OR
+					(b.blocked_id = $6 AND b.blocker_id IN (c.user_id, p.user_id))
+			)
+			ORDER BY rank DESC, created_at DESC, id DESC
+			LIMIT $4 OFFSET $5
+		)
+		SELECT
+			page.type, page.id, page.post_id, page.user_id, page.title,
+			ts_headline($1::regconfig, page.content, search.q, 'MaxFragments=2, MaxWords=20, MinWords=5') AS snippet,
+			page.rank, page.created_at
+		FROM page, search
+		ORDER BY page.rank DESC, page.created_at DESC, page.id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, q.Language, q.Query, q.Type, q.Limit, q.Offset, q.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var result SearchResult
+		err := rows.Scan(
+			&result.Type,
+			&result.ID,
+			&result.PostID,
+			&result.UserID,
+			&result.Title,
+			&result.Snippet,
+			&result.Rank,
+			&result.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
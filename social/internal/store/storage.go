@@ -4,35 +4,111 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 )
 
 var (
 	ErrNotFound          = errors.New("resource not found")
 	ErrConflict          = errors.New("resource already exists")
+	ErrEditConflict      = errors.New("resource was modified by another request")
+	ErrDuplicateEmail    = errors.New("a user with that email already exists")
+	ErrDuplicateUsername = errors.New("a user with that username already exists")
+	ErrSelfFollow        = errors.New("users cannot follow themselves")
+	ErrSelfBlock         = errors.New("users cannot block themselves")
+	ErrSelfMute          = errors.New("users cannot mute themselves")
+	ErrReferenceNotFound = errors.New("referenced resource not found")
+	ErrCheckViolation    = errors.New("value violates a check constraint")
+	ErrNotNullViolation  = errors.New("required value is missing")
 	QueryTimeoutDuration = time.Second * 5
 )
 
 type Storage struct {
-	Posts interface {
-		Create(context.Context, *Post) error
-		GetByID(context.Context, int64) (*Post, error)
-		Update(context.Context, *Post) error
-		Delete(context.Context, int64) error
-		GetUserFeed(context.Context, int64, Pagination) ([]PostWithMetadata, error)
-	}
-	Users interface {
-		Create(context.Context, *User) error
-		GetByID(context.Context, int64) (*User, error)
-	}
-	Comments interface {
-		Create(context.Context, *Comment) error
-		GetByPostID(context.Context, int64) ([]Comment, error)
-	}
-	Followers interface {
-		Follow(context.Context, int64, int64) error
-		UnFollow(context.Context, int64, int64) error
-	}
+	Posts     PostRepository
+	Users     UserRepository
+	Comments  CommentRepository
+	Followers FollowerRepository
+	Blocks    BlockRepository
+	Mutes     MuteRepository
+	Roles     RoleRepository
+	Search    SearchRepository
+	Reactions ReactionRepository
+}
+
+type PostRepository interface {
+	Create(context.Context, *Post) error
+	GetByID(context.Context, int64) (*Post, error)
+	Update(context.Context, *Post) error
+	Delete(context.Context, int64) error
+	GetUserFeed(context.Context, int64, Pagination) (*Feed, error)
+}
+
+type UserRepository interface {
+	Create(context.Context, *User) error
+	GetByID(context.Context, int64) (*User, error)
+	GetByEmail(context.Context, string) (*User, error)
+	CreateAndInvite(context.Context, *User, string, time.Duration) error
+	Activate(context.Context, string) (*User, error)
+	GetProfile(context.Context, *User, int64) (*UserProfile, error)
+	UpdateProfile(context.Context, *User) error
+	UpdatePassword(context.Context, *User) error
+	CreateEmailChange(context.Context, int64, string, string, time.Duration) error
+	ConfirmEmailChange(context.Context, string) (*User, error)
+	DeleteEmailChange(context.Context, string) error
+	Delete(context.Context, int64) error
+	CreatePasswordReset(context.Context, int64, string, time.Duration) error
+	DeletePasswordReset(context.Context, string) error
+	ResetPassword(context.Context, string, string) (*User, error)
+}
+
+type CommentRepository interface {
+	Create(context.Context, *Comment) error
+	GetByID(context.Context, int64) (*Comment, error)
+	GetByPostID(context.Context, int64, int64, Pagination) (*CommentPage, error)
+	GetReplies(context.Context, int64, int64, Pagination) (*CommentPage, error)
+	CountByPostID(context.Context, int64) (int, error)
+	Update(context.Context, *Comment) error
+	Delete(context.Context, int64) error
+}
+
+type FollowerRepository interface {
+	Follow(context.Context, int64, int64) error
+	UnFollow(context.Context, int64, int64) error
+	GetFollowers(context.Context, int64, Pagination) (*FollowerPage, error)
+	GetFollowing(context.Context, int64, Pagination) (*FollowerPage, error)
+}
+
+type BlockRepository interface {
+	Block(context.Context, int64, int64) error
+	Unblock(context.Context, int64, int64) error
+	IsBlocked(context.Context, int64, int64) (bool, error)
+}
+
+type MuteRepository interface {
+	Mute(context.Context, int64, int64) error
+	Unmute(context.Context, int64, int64) error
+}
+
+type RoleRepository interface {
+	GetByName(context.Context, string) (*Role, error)
+}
+
+type SearchRepository interface {
+	Search(context.Context, SearchQuery) ([]SearchResult, error)
+}
+
+type ReactionRepository interface {
+	Add(context.Context, *Reaction) error
+	Remove(context.Context, *Reaction) error
+	Counts(context.Context, string, []int64) (map[int64]map[string]int, error)
+}
+
+// DBTX runs queries; it is implemented by both *sql.DB and *sql.Tx, so stores
+// work the same inside and outside a transaction.
+type DBTX interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...any) *sql.Row
 }
 
 func NewStorage(db *sql.DB) Storage {
@@ -41,5 +117,37 @@ func NewStorage(db *sql.DB) Storage {
 		Users:     &UserStore{db},
 		Comments:  &CommentStore{db},
 		Followers: &FollowerStore{db},
+		Blocks:    &BlockStore{db},
+		Mutes:     &MuteStore{db},
+		Roles:     &RoleStore{db},
+		Search:    &SearchStore{db},
+		Reactions: &ReactionStore{db},
 	}
 }
+
+// withTx runs fn in a new transaction, or in db itself when it already is one,
+// so store methods can be composed into larger transactions.
+func withTx(db DBTX, ctx context.Context, fn func(*sql.Tx) error) error {
+	if tx, ok := db.(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	beginner, ok := db.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return fmt.Errorf("store: %T can't begin transactions", db)
+	}
+
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -2,20 +2,42 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	ID        int64  `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	Password  string `json:"-"`
-	CreatedAt string `json:"created_at"`
+	ID        int64    `json:"id"`
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	Password  password `json:"-"`
+	IsActive  bool     `json:"is_active"`
+	RoleID    int64    `json:"role_id"`
+	Role      Role     `json:"role"`
+	CreatedAt string   `json:"created_at"`
+
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+	Website     string `json:"website"`
+}
+
+// UserProfile is a user as shown on their profile page, relative to the
+// viewing user.
+type UserProfile struct {
+	User
+	FollowersCount int  `json:"followers_count"`
+	FollowingCount int  `json:"following_count"`
+	PostsCount     int  `json:"posts_count"`
+	IsFollowing    bool `json:"is_following"`
 }
+
 type UserStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 type password struct {
@@ -40,36 +62,144 @@ func (p *password) Compare(text string) error {
 }
 
 func (s *UserStore) Create(ctx context.Context, user *User) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.create(ctx, tx, user)
+	})
+}
+
+func (s *UserStore) create(ctx context.Context, tx *sql.Tx, user *User) error {
 	query := `
-			INSERT INTO users (username, email, password)
-			VALUES ($1, $2, $3)
-			RETURNING id, created_at
+			INSERT INTO users (username, email, password, is_active, role_id)
+			VALUES ($1, $2, $3, $4, (SELECT id FROM roles WHERE name = $5))
+			RETURNING id, role_id, created_at
 		`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	err := s.db.QueryRowContext(ctx, query, user.Username, user.Email, user.Password).Scan(&user.ID, &user.CreatedAt)
+	role := user.Role.Name
+	if role == "" {
+		role = "user"
+	}
+
+	err := tx.QueryRowContext(ctx, query, user.Username, user.Email, user.Password.hash, user.IsActive, role).Scan(
+		&user.ID,
+		&user.RoleID,
+		&user.CreatedAt,
+	)
 
 	if err != nil {
+		err = translateError(err)
+		switch violatedConstraint(err) {
+		case "users_email_key":
+			return ErrDuplicateEmail
+		case "users_username_key":
+			return ErrDuplicateUsername
+		}
 		return err
 	}
 
 	return nil
 }
 
+// GetByID returns the user with its role and, like GetByEmail, its password
+// hash.
 func (s *UserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 	query := `
-			SELECT id, username, email, created_at
+			SELECT
+				u.id, u.username, u.email, u.password, u.is_active, u.created_at,
+				u.display_name, u.bio, u.avatar_url, u.website,
+				r.id, r.name, r.level, COALESCE(r.description, '')
+			FROM users u
+			JOIN roles r ON r.id = u.role_id
+			WHERE u.id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var user User
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password.hash,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.DisplayName,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.Website,
+		&user.Role.ID,
+		&user.Role.Name,
+		&user.Role.Level,
+		&user.Role.Description,
+	)
+
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	user.RoleID = user.Role.ID
+
+	return &user, nil
+}
+
+// GetProfile adds the profile counts to an already loaded user. IsFollowing
+// reports whether viewerID follows them; pass 0 for anonymous viewers.
+func (s *UserStore) GetProfile(ctx context.Context, user *User, viewerID int64) (*UserProfile, error) {
+	query := `
+			SELECT
+				(SELECT COUNT(*) FROM followers WHERE user_id = $1),
+				(SELECT COUNT(*) FROM followers WHERE follower_id = $1),
+				(SELECT COUNT(*) FROM posts WHERE user_id = $1),
+				EXISTS (SELECT 1 FROM followers WHERE user_id = $1 AND follower_id = $2)
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	profile := UserProfile{User: *user}
+	err := s.db.QueryRowContext(ctx, query, user.ID, viewerID).Scan(
+		&profile.FollowersCount,
+		&profile.FollowingCount,
+		&profile.PostsCount,
+		&profile.IsFollowing,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// GetByEmail returns the user together with its password hash, so callers can
+// verify credentials with Password.Compare.
+func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+			SELECT id, username, email, password, is_active, role_id, created_at
 			FROM users
-			WHERE id = $1
+			WHERE email = $1
 		`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	var user User
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt)
+	err := s.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password.hash,
+		&user.IsActive,
+		&user.RoleID,
+		&user.CreatedAt,
+	)
 
 	if err != nil {
 		switch err {
@@ -82,3 +212,353 @@ func (s *UserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 
 	return &user, nil
 }
+
+// CreateAndInvite creates the user and its invitation in a single transaction,
+// so a user never exists without a way to activate the account.
+func (s *UserStore) CreateAndInvite(ctx context.Context, user *User, token string, invitationExp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.create(ctx, tx, user); err != nil {
+			return err
+		}
+
+		return s.createUserInvitation(ctx, tx, token, invitationExp, user.ID)
+	})
+}
+
+func (s *UserStore) createUserInvitation(ctx context.Context, tx *sql.Tx, token string, exp time.Duration, userID int64) error {
+	query := `INSERT INTO user_invitations (token, user_id, expiry) VALUES ($1, $2, $3)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, hashToken(token), userID, time.Now().Add(exp))
+	return translateError(err)
+}
+
+// Activate marks the user owning a valid, unexpired invitation token as active,
+// removes its invitations and returns the activated user.
+func (s *UserStore) Activate(ctx context.Context, token string) (*User, error) {
+	var user *User
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var err error
+		user, err = s.getUserFromInvitation(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+
+		user.IsActive = true
+		if err := s.update(ctx, tx, user); err != nil {
+			return err
+		}
+
+		return s.deleteUserInvitations(ctx, tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserStore) getUserFromInvitation(ctx context.Context, tx *sql.Tx, token string) (*User, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.is_active, u.created_at
+		FROM users u
+		JOIN user_invitations ui ON u.id = ui.user_id
+		WHERE ui.token = $1 AND ui.expiry > $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var user User
+	err := tx.QueryRowContext(ctx, query, hashToken(token), time.Now()).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.IsActive,
+		&user.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+func (s *UserStore) update(ctx context.Context, tx *sql.Tx, user *User) error {
+	query := `UPDATE users SET username = $1, email = $2, is_active = $3 WHERE id = $4`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, user.Username, user.Email, user.IsActive, user.ID)
+	if err != nil {
+		err = translateError(err)
+		switch violatedConstraint(err) {
+		case "users_email_key":
+			return ErrDuplicateEmail
+		case "users_username_key":
+			return ErrDuplicateUsername
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserStore) deleteUserInvitations(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM user_invitations WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
+
+// UpdateProfile saves the user's display name, bio, avatar URL and website.
+func (s *UserStore) UpdateProfile(ctx context.Context, user *User) error {
+	query := `
+			UPDATE users
+			SET display_name = $1, bio = $2, avatar_url = $3, website = $4
+			WHERE id = $5
+		`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, user.DisplayName, user.Bio, user.AvatarURL, user.Website, user.ID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdatePassword saves the hash of the password last set on the user.
+func (s *UserStore) UpdatePassword(ctx context.Context, user *User) error {
+	query := `UPDATE users SET password = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, user.Password.hash, user.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateEmailChange stores a request to change the user's email, confirmed with
+// token. It replaces any pending request of the user.
+func (s *UserStore) CreateEmailChange(ctx context.Context, userID int64, email string, token string, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.deleteEmailChanges(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO user_email_changes (token, user_id, email, expiry) VALUES ($1, $2, $3, $4)`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		_, err := tx.ExecContext(ctx, query, hashToken(token), userID, email, time.Now().Add(exp))
+		return translateError(err)
+	})
+}
+
+// ConfirmEmailChange applies the pending, unexpired email change identified by
+// token and returns the updated user.
+func (s *UserStore) ConfirmEmailChange(ctx context.Context, token string) (*User, error) {
+	var user User
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE users u
+			SET email = ec.email
+			FROM user_email_changes ec
+			WHERE ec.token = $1 AND ec.expiry > $2 AND u.id = ec.user_id
+			RETURNING u.id, u.username, u.email, u.is_active, u.created_at
+		`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, hashToken(token), time.Now()).Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.IsActive,
+			&user.CreatedAt,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			case violatedConstraint(translateError(err)) == "users_email_key":
+				return ErrDuplicateEmail
+			default:
+				return err
+			}
+		}
+
+		return s.deleteEmailChanges(ctx, tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// DeleteEmailChange removes a pending email change, e.g. when its
+// confirmation couldn't be sent.
+func (s *UserStore) DeleteEmailChange(ctx context.Context, token string) error {
+	query := `DELETE FROM user_email_changes WHERE token = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, hashToken(token))
+	return err
+}
+
+func (s *UserStore) deleteEmailChanges(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM user_email_changes WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
+
+// CreatePasswordReset stores a one-time password reset token for the user.
+func (s *UserStore) CreatePasswordReset(ctx context.Context, userID int64, token string, exp time.Duration) error {
+	query := `INSERT INTO password_resets (token, user_id, expiry) VALUES ($1, $2, $3)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, hashToken(token), userID, time.Now().Add(exp))
+	return translateError(err)
+}
+
+// DeletePasswordReset removes a reset token, e.g. when it couldn't be sent.
+func (s *UserStore) DeletePasswordReset(ctx context.Context, token string) error {
+	query := `DELETE FROM password_resets WHERE token = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, hashToken(token))
+	return err
+}
+
+// ResetPassword sets a new password for the user owning a valid, unexpired
+// reset token, and invalidates all of their outstanding reset tokens.
+func (s *UserStore) ResetPassword(ctx context.Context, token string, newPassword string) (*User, error) {
+	var user User
+
+	if err := user.Password.Set(newPassword); err != nil {
+		return nil, err
+	}
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE users u
+			SET password = $1
+			FROM password_resets pr
+			WHERE pr.token = $2 AND pr.expiry > $3 AND u.id = pr.user_id
+			RETURNING u.id, u.username, u.email, u.is_active, u.created_at
+		`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, user.Password.hash, hashToken(token), time.Now()).Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.IsActive,
+			&user.CreatedAt,
+		)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		query = `DELETE FROM password_resets WHERE user_id = $1`
+
+		_, err = tx.ExecContext(ctx, query, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Delete removes the user. Their posts, comments, follows, reactions and
+// pending tokens go with them through ON DELETE CASCADE; other users' replies
+// to their comments are kept as top-level comments.
+func (s *UserStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM users WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// hashToken returns the SHA-256 digest stored in place of a plain token.
+func hashToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}